@@ -0,0 +1,84 @@
+package ga
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Ack tracks the delivery of a single reported Event.
+// It resolves when the batch containing the Event received a response from GA,
+// or when the Event failed permanently.
+// Events dropped by a Processor are never submitted, their Ack resolves with ErrEventDropped.
+type Ack struct {
+	done chan struct{}
+	err  error
+	once sync.Once
+}
+
+func newAck() *Ack {
+	return &Ack{done: make(chan struct{})}
+}
+
+func (a *Ack) resolve(err error) {
+	a.once.Do(func() {
+		a.err = err
+		close(a.done)
+	})
+}
+
+// Done returns a channel that is closed when the Ack resolves.
+func (a *Ack) Done() <-chan struct{} {
+	return a.done
+}
+
+// Err returns the error the Event failed with.
+// It returns nil if the Event was submitted or the Ack has not resolved yet.
+func (a *Ack) Err() error {
+	select {
+	case <-a.done:
+		return a.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the Ack resolves or ctx is done.
+func (a *Ack) Wait(ctx context.Context) error {
+	select {
+	case <-a.done:
+		return a.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReportWithAck is used to submit an Event to GA and track its delivery.
+// Unlike Report it returns an Ack that resolves once the Event left the process or failed permanently.
+// Errors passed to the ErrHandler are also returned by the Ack.
+// This can be safely called by multiple go routines.
+func (c *Client) ReportWithAck(e Event) (*Ack, error) {
+	a := newAck()
+
+	err := c.report(event{
+		reportedAt: time.Now(),
+		ack:        a,
+		e:          e,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// ReportSync is used to submit an Event to GA and blocks until it was delivered, it failed or ctx is done.
+// This can be safely called by multiple go routines.
+func (c *Client) ReportSync(ctx context.Context, e Event) error {
+	a, err := c.ReportWithAck(e)
+	if err != nil {
+		return err
+	}
+
+	return a.Wait(ctx)
+}
@@ -0,0 +1,131 @@
+package ga
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Client_ReportSync(t *testing.T) {
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("fail") != "" {
+				http.Error(w, "bad", 400)
+				return
+			}
+			w.WriteHeader(200)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Millisecond * 50,
+	}
+
+	c.urlStr = ts.URL
+
+	go func() {
+		err := c.Start()
+		if err != nil && err != ErrClientClosed {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := c.ReportSync(ctx, Event{"foo": "baz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.urlStr = ts.URL + "?fail=1"
+
+	a, err := c.ReportWithAck(Event{"foo": "baz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Err() != nil {
+		t.Fatal(a.Err())
+	}
+
+	select {
+	case <-a.Done():
+	case <-ctx.Done():
+		t.Fatal("expected ack")
+	}
+
+	var dErr *DeliveryError
+	if !errors.As(a.Err(), &dErr) || dErr.StatusCode != 400 {
+		t.Fatal(a.Err())
+	}
+
+	err = c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.ReportWithAck(Event{"foo": "baz"})
+	if err != ErrClientClosed {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Client_ReportSync_Dropped(t *testing.T) {
+
+	c := &Client{}
+
+	c.Use(ProcessorFunc(func(e Event) Event {
+		return nil
+	}))
+
+	a, err := c.ReportWithAck(Event{"t": "transaction", "ti": "T1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Err() != ErrEventDropped {
+		t.Fatal(a.Err())
+	}
+
+	err = c.ReportSync(context.Background(), Event{"t": "transaction", "ti": "T2"})
+	if err != ErrEventDropped {
+		t.Fatal(err)
+	}
+
+	if len(c.pending) != 0 {
+		t.Fatal(len(c.pending))
+	}
+
+}
+
+func Test_Ack_Wait_Canceled(t *testing.T) {
+
+	a := newAck()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := a.Wait(ctx)
+	if err != context.Canceled {
+		t.Fatal(err)
+	}
+
+	a.resolve(nil)
+	a.resolve(ErrClientClosed)
+
+	err = a.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
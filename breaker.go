@@ -0,0 +1,123 @@
+package ga
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker of a Client.
+type BreakerState int
+
+const (
+	// BreakerClosed means batches are submitted as usual.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means the endpoint is failing and no batches are submitted.
+	// Events remain buffered until the breaker closes.
+	BreakerOpen
+	// BreakerHalfOpen means a single batch is submitted to probe the endpoint.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// breaker opens after threshold consecutive failures and allows a probe after cooldown.
+// The zero breaker never opens.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+}
+
+func (b *breaker) configure(threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.threshold = threshold
+	b.cooldown = cooldown
+}
+
+// allow reports whether a batch may be submitted.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		// a probe is in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record registers the outcome of a submitted batch.
+// It reports whether the breaker opened because of it.
+func (b *breaker) record(now time.Time, ok bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		b.state = BreakerClosed
+		b.failures = 0
+		return false
+	}
+
+	b.failures++
+
+	if b.threshold <= 0 {
+		return false
+	}
+
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		opened := b.state != BreakerOpen
+		b.state = BreakerOpen
+		b.openedAt = now
+		return opened
+	}
+
+	return false
+}
+
+// until returns when the breaker allows a probe.
+func (b *breaker) until() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerOpen {
+		return time.Time{}
+	}
+
+	return b.openedAt.Add(b.cooldown)
+}
+
+func (b *breaker) getState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// BreakerState returns the state of the circuit breaker of the Client.
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.getState()
+}
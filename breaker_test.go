@@ -0,0 +1,295 @@
+package ga
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Breaker(t *testing.T) {
+
+	b := &breaker{}
+	b.configure(2, time.Minute)
+
+	now := time.Now()
+
+	if !b.allow(now) || b.record(now, false) || b.getState() != BreakerClosed {
+		t.Fatal(b.getState())
+	}
+
+	if !b.allow(now) || !b.record(now, false) || b.getState() != BreakerOpen {
+		t.Fatal(b.getState())
+	}
+
+	if b.allow(now.Add(time.Second)) {
+		t.Fatal("expected open breaker to refuse")
+	}
+
+	if !b.until().Equal(now.Add(time.Minute)) {
+		t.Fatal(b.until())
+	}
+
+	// probe
+	now = now.Add(time.Minute)
+	if !b.allow(now) || b.getState() != BreakerHalfOpen {
+		t.Fatal(b.getState())
+	}
+	if b.allow(now) {
+		t.Fatal("expected a single probe")
+	}
+
+	// failed probe opens again
+	if !b.record(now, false) || b.getState() != BreakerOpen {
+		t.Fatal(b.getState())
+	}
+
+	// successful probe closes
+	now = now.Add(time.Minute)
+	if !b.allow(now) || b.record(now, true) || b.getState() != BreakerClosed {
+		t.Fatal(b.getState())
+	}
+
+	if BreakerHalfOpen.String() != "half-open" {
+		t.Fatal(BreakerHalfOpen.String())
+	}
+
+}
+
+func Test_Client_Breaker_Attempts(t *testing.T) {
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad", 400)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		HTTP:         http.DefaultClient,
+		MaxQueueTime: time.Hour,
+	}
+	c.urlStr = ts.URL
+
+	now := time.Now()
+	c.breaker.configure(1, time.Minute)
+	c.breaker.record(now, false)
+
+	l := events{{reportedAt: now, e: Event{"foo": "baz"}}}
+
+	for i := 0; i < 3; i++ {
+		_, err := c.sendBatch(context.Background(), l)
+		if err != ErrCircuitOpen {
+			t.Fatal(err)
+		}
+	}
+
+	if l[0].attempts != 0 {
+		t.Fatal(l[0].attempts)
+	}
+
+	var dErr *DeliveryError
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		errors.As(err, &dErr)
+	}))
+
+	c.breaker.record(now.Add(-time.Hour), true)
+
+	_, err := c.sendBatch(context.Background(), l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dErr == nil || dErr.Attempts != 1 {
+		t.Fatal(dErr)
+	}
+
+}
+
+func Test_Client_Breaker_MaxBufferedEvents(t *testing.T) {
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "down", 503)
+		}),
+	)
+	defer ts.Close()
+
+	errChan := make(chan error, 10)
+
+	c := &Client{
+		BatchWait:         time.Hour,
+		MinBatchSize:      1,
+		MaxBufferedEvents: 5,
+		BreakerThreshold:  1,
+		BreakerCooldown:   time.Hour,
+	}
+
+	c.urlStr = ts.URL
+
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		errChan <- err
+	}))
+
+	go c.Start()
+
+	time.Sleep(time.Millisecond * 10)
+
+	err := c.ReportSync(context.Background(), Event{"foo": "baz"})
+	if !errors.Is(err, ErrGoogleAnalytics) || c.BreakerState() != BreakerOpen {
+		t.Fatal(err, c.BreakerState())
+	}
+
+	for i := 0; i < 5; i++ {
+		err := c.Report(Event{"foo": "buffered"})
+		if err != nil {
+			t.Fatal(i, err)
+		}
+	}
+
+	time.Sleep(time.Millisecond * 10)
+
+	for len(errChan) > 0 {
+		<-errChan
+	}
+
+	a, err := c.ReportWithAck(Event{"foo": "overflow"})
+	if err != ErrBufferFull {
+		t.Fatal(err)
+	}
+	if a != nil {
+		t.Fatal(a)
+	}
+
+	select {
+	case err := <-errChan:
+		if err != ErrBufferFull {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("expected err")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	err = c.Shutdown(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Breaker_Disabled(t *testing.T) {
+
+	b := &breaker{}
+
+	for i := 0; i < 100; i++ {
+		if !b.allow(time.Now()) || b.record(time.Now(), false) {
+			t.Fatal(b.getState())
+		}
+	}
+
+}
+
+func Test_Client_Breaker(t *testing.T) {
+
+	var (
+		healthy bool
+		mu      sync.Mutex
+	)
+
+	reqChan := make(chan struct{}, 10)
+	errChan := make(chan error, 10)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			reqChan <- struct{}{}
+
+			if !healthy {
+				http.Error(w, "down", 503)
+				return
+			}
+			w.WriteHeader(200)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait:        time.Millisecond * 20,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Millisecond * 200,
+	}
+
+	c.urlStr = ts.URL
+
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		errChan <- err
+	}))
+
+	go c.Start()
+
+	time.Sleep(time.Millisecond * 10)
+
+	for i := 0; i < 2; i++ {
+		err := c.ReportSync(context.Background(), Event{"foo": "baz"})
+		if !errors.Is(err, ErrGoogleAnalytics) {
+			t.Fatal(err)
+		}
+	}
+
+	if c.BreakerState() != BreakerOpen {
+		t.Fatal(c.BreakerState())
+	}
+
+	var opened bool
+	for len(errChan) > 0 {
+		if errors.Cause(<-errChan) == ErrCircuitOpen {
+			opened = true
+		}
+	}
+	if !opened {
+		t.Fatal("expected ErrCircuitOpen")
+	}
+
+	for len(reqChan) > 0 {
+		<-reqChan
+	}
+
+	mu.Lock()
+	healthy = true
+	mu.Unlock()
+
+	a, err := c.ReportWithAck(Event{"foo": "baz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	if len(reqChan) != 0 {
+		t.Fatal("expected no requests while open")
+	}
+
+	err = a.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.BreakerState() != BreakerClosed {
+		t.Fatal(c.BreakerState())
+	}
+
+	err = c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
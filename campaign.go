@@ -0,0 +1,82 @@
+package ga
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultCampaignParams maps the query parameters of campaign links to the campaign parameters of Events.
+var DefaultCampaignParams = map[string]string{
+	"utm_source":   "cs",
+	"utm_medium":   "cm",
+	"utm_campaign": "cn",
+	"utm_term":     "ck",
+	"utm_content":  "cc",
+	"utm_id":       "ci",
+	"gclid":        "gclid",
+	"dclid":        "dclid",
+}
+
+// campaignParam returns the Event parameter query parameter key maps to.
+// Mapping a key of DefaultCampaignParams to "" in custom disables it.
+func campaignParam(custom map[string]string, key string) (string, bool) {
+	if param, ok := custom[key]; ok {
+		return param, param != ""
+	}
+	param, ok := DefaultCampaignParams[key]
+	return param, ok
+}
+
+// campaign returns the campaign parameters of the query of u.
+func campaign(u *url.URL, custom map[string]string) Event {
+	e := Event{}
+
+	for key, values := range u.Query() {
+		param, ok := campaignParam(custom, key)
+		if !ok || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		e.Set(param, values[0])
+	}
+
+	return e
+}
+
+// stripQuery returns the request URI of u without the query parameters drop reports true for.
+// The order and encoding of the other query parameters are kept.
+func stripQuery(u *url.URL, drop func(key string) bool) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+
+	stripped := *u
+	stripped.RawQuery = filterQuery(u.RawQuery, drop)
+	stripped.ForceQuery = false
+	return stripped.RequestURI()
+}
+
+// filterQuery returns rawQuery without the parameters drop reports true for,
+// keeping the order and encoding of the others.
+func filterQuery(rawQuery string, drop func(key string) bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		key := pair
+		if i := strings.Index(pair, "="); i >= 0 {
+			key = pair[:i]
+		}
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+
+		if drop(key) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+
+	return strings.Join(kept, "&")
+}
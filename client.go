@@ -4,21 +4,35 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math/rand"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 )
 
+// DefaultMaxBufferedEvents is the default maximum number of Events a Client buffers.
+const DefaultMaxBufferedEvents = 10000
+
 // Client reports Events to GA.
+//
+// A Client is new until Start is called, running until Shutdown is called,
+// draining while Shutdown submits the remaining Events and stopped afterwards.
+// Events reported to a new Client are buffered until it is started.
+// A stopped Client can be started again.
 type Client struct {
-	// How long the client waits before reporting an Event to GA.
+	// The longest time an Event is buffered before the Client submits it to GA.
+	// Failed submissions are retried after BatchWait.
 	// The default is 15 seconds.
 	BatchWait time.Duration
+	// The number of buffered Events that makes the Client submit them without waiting for BatchWait.
+	// The default and maximum is 20, the largest batch GA accepts.
+	MinBatchSize int
 	// HTTP is the http.Client used to make POST calls to GA.
 	HTTP *http.Client
 	// The time to wait for batch sends to complete.
@@ -26,21 +40,67 @@ type Client struct {
 	// Client.Shutdown will wait in steps of SendTimeout until all Events have been submitted.
 	// The defaults to http.Client.Timeout if this is zero it will default to 5 seconds.
 	SendTimeout time.Duration
+	// Events reported longer ago than MaxQueueTime are never submitted,
+	// they are passed to the ErrHandler with ErrQueueTimeExceeded instead.
+	// The default is DefaultMaxQueueTime.
+	MaxQueueTime time.Duration
+	// The number of consecutive failed submissions that opens the circuit breaker.
+	// While the breaker is open Events remain buffered and no submissions are made,
+	// after BreakerCooldown a single batch probes the endpoint.
+	// Timeouts, connection errors and 5xx responses count as failures.
+	// Zero disables the circuit breaker.
+	BreakerThreshold int
+	// How long the circuit breaker stays open.
+	// The default is 30 seconds.
+	BreakerCooldown time.Duration
+	// The longest time Run waits for the remaining Events to be submitted after its context is done.
+	// Events that are not submitted in time are dropped.
+	// The default is 30 seconds.
+	ShutdownTimeout time.Duration
+	// The maximum number of Events the Client buffers, for example while the circuit breaker is open.
+	// Events reported while the buffer is full are passed to the ErrHandler with ErrBufferFull.
+	// The default is DefaultMaxBufferedEvents.
+	MaxBufferedEvents int
+	// CacheBust adds a random cache buster ("z") to every submitted Event that doesn't carry one.
+	CacheBust bool
 	// The GA ID for Events.
-	// This is only used by Client.DefaultHTTPHandler.
+	// This is only used by Middleware and the Events the Client builds itself, such as timing hits.
 	TID string
-
-	doneChan     chan struct{}
-	errHandler   ErrHandler // useful for logging errors occurring on ga go routines
-	eventChan    chan event
-	eventCounter int32 // accessed atomically
-	events       events
-	inShutdown   int32 // accessed atomically (non-zero means we're in Shutdown).
-	mu           sync.Mutex
-	started      int32  // accessed atomically (non-zero means we've Started).
-	urlStr       string // set to httptest.NewServer().URL during tests.
+	// The client id of the Events the Client builds itself.
+	// The default is a random id, generated once.
+	CID string
+	// Registry names the custom dimensions and metrics of the GA property, see Event.SetNamed.
+	// When set, reported Events are checked with Registry.Validate after the Processors ran.
+	// Events that fail the check are still submitted, the error is passed to the ErrHandler.
+	Registry *Registry
+
+	abortChan   chan struct{} // closed when Shutdown gives up on draining.
+	breaker     breaker
+	buffered    int           // Events held by the Start loop, guarded by mu.
+	doneChan    chan struct{} // closed when Shutdown is called.
+	errHandler  ErrHandler    // useful for logging errors occurring on ga go routines
+	events      events        // only accessed by the Start loop.
+	flushChan   chan chan error
+	groups      int // sequence of Event groups, guarded by mu.
+	mu          sync.Mutex
+	pending     events // reported but not yet received by the Start loop, guarded by mu.
+	processors  []Processor
+	retryAt     time.Time // only accessed by the Start loop.
+	state       clientState
+	stoppedChan chan struct{} // closed when the Start loop returns.
+	urlStr      string        // set to httptest.NewServer().URL during tests.
+	wakeChan    chan struct{}
 }
 
+type clientState int
+
+const (
+	stateNew clientState = iota
+	stateRunning
+	stateDraining
+	stateStopped
+)
+
 func (c *Client) getDoneChan() <-chan struct{} {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -66,50 +126,148 @@ func (c *Client) closeDoneChanLocked() {
 	}
 }
 
-func (c *Client) closeEventChanLocked() {
-	close(c.eventChan)
+func (c *Client) getWakeChanLocked() chan struct{} {
+	if c.wakeChan == nil {
+		c.wakeChan = make(chan struct{}, 1)
+	}
+	return c.wakeChan
+}
+
+func (c *Client) getFlushChanLocked() chan chan error {
+	if c.flushChan == nil {
+		c.flushChan = make(chan chan error)
+	}
+	return c.flushChan
 }
 
 // Shutdown the Client.
-// This will block until all Events reported before calling Shutdown have been submitted.
+// This will block until all Events reported before calling Shutdown have been submitted, or ctx is done.
+// If ctx is done first, the Events that were not submitted are passed to the ErrHandler with ErrClientClosed.
 func (c *Client) Shutdown(ctx context.Context) error {
-	atomic.AddInt32(&c.inShutdown, 1)
-	defer atomic.AddInt32(&c.inShutdown, -1)
-
 	c.mu.Lock()
-	c.closeDoneChanLocked()
-	c.mu.Unlock()
 
-	for c.sending() {
-		time.Sleep(c.HTTP.Timeout)
+	switch c.state {
+	case stateNew, stateStopped:
+		c.state = stateStopped
+		c.closeDoneChanLocked()
+		pending := c.pending
+		c.pending = nil
+		c.mu.Unlock()
+
+		if len(pending) > 0 {
+			c.handleErr(pending.cleanEvents(), ErrClientClosed)
+			pending.resolve(ErrClientClosed)
+		}
+		return nil
+	case stateRunning:
+		c.state = stateDraining
+		c.closeDoneChanLocked()
 	}
 
-	c.closeEventChanLocked()
+	stopped := c.stoppedChan
+	abort := c.abortChan
+	c.mu.Unlock()
 
-	return nil
-}
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		select {
+		case <-abort:
+		default:
+			close(abort)
+		}
+		c.mu.Unlock()
 
-func (c *Client) sending() bool {
-	if atomic.LoadInt32(&c.eventCounter) > 0 {
-		return true
+		<-stopped
+		return ctx.Err()
 	}
-
-	return false
 }
 
 // Start makes the Client receive Events and submit them to GA.
 // This call will block until Client.Shutdown is called.
 func (c *Client) Start() error {
-	if atomic.LoadInt32(&c.started) > 0 {
+	err := c.start()
+	if err != nil {
+		return err
+	}
+
+	return c.serve()
+}
+
+// Run starts the Client and blocks until ctx is done, after which the Client is shut down gracefully.
+// It returns nil once all Events have been submitted, which makes it a good fit for errgroup style service lifecycles.
+// If the Events can't be submitted within ShutdownTimeout, Run gives up and returns context.DeadlineExceeded.
+func (c *Client) Run(ctx context.Context) error {
+	err := c.start()
+	if err != nil {
+		return err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- c.serve()
+	}()
+
+	select {
+	case <-errChan:
+		// shut down by a call to Shutdown
+		return nil
+	case <-ctx.Done():
+	}
+
+	timeout := c.ShutdownTimeout
+	if timeout == 0 {
+		timeout = time.Second * 30
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = c.Shutdown(shutdownCtx)
+	<-errChan
+
+	return err
+}
+
+// start moves the Client into the running state.
+func (c *Client) start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == stateRunning || c.state == stateDraining {
 		return ErrAlreadyStarted
 	}
 
-	atomic.AddInt32(&c.started, 1)
-	defer atomic.AddInt32(&c.started, -1)
+	if c.state == stateStopped {
+		c.doneChan = nil
+	}
 
-	c.events = make([]event, 0, 256)
+	c.state = stateRunning
+	c.buffered = 0
+	c.abortChan = make(chan struct{})
+	c.stoppedChan = make(chan struct{})
+
+	return nil
+}
+
+// serve is the loop of a running Client.
+func (c *Client) serve() error {
+	c.mu.Lock()
+	done := c.getDoneChanLocked()
+	flush := c.getFlushChanLocked()
+	wake := c.getWakeChanLocked()
+	c.mu.Unlock()
 
-	c.eventChan = make(chan event)
+	defer func() {
+		c.mu.Lock()
+		c.state = stateStopped
+		close(c.stoppedChan)
+		c.mu.Unlock()
+	}()
+
+	c.events = make([]event, 0, 256)
 
 	if c.HTTP == nil {
 		c.HTTP = http.DefaultClient
@@ -127,6 +285,10 @@ func (c *Client) Start() error {
 		c.SendTimeout = time.Second * 5
 	}
 
+	if c.MaxQueueTime == 0 {
+		c.MaxQueueTime = DefaultMaxQueueTime
+	}
+
 	if c.urlStr == "" {
 		c.urlStr = "https://www.google-analytics.com/batch"
 	}
@@ -135,57 +297,329 @@ func (c *Client) Start() error {
 		c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {}))
 	}
 
-	ticker := time.NewTicker(c.BatchWait)
-	defer ticker.Stop()
+	if c.BreakerCooldown == 0 {
+		c.BreakerCooldown = time.Second * 30
+	}
+
+	c.breaker.configure(c.BreakerThreshold, c.BreakerCooldown)
+
+	if c.MinBatchSize <= 0 || c.MinBatchSize > 20 {
+		c.MinBatchSize = 20
+	}
+
+	// The timer only runs while Events are buffered, an idle Client does not wake up.
+	timer := time.NewTimer(c.BatchWait)
+	defer timer.Stop()
+
+	// Events reported before Start.
+	c.receive()
 
 	for {
 		select {
-		case e := <-c.eventChan:
-
-			atomic.AddInt32(&c.eventCounter, 1)
-			c.events = append(c.events, e)
-
-			if len(c.events) >= 20 {
-				n, _ := c.send(c.events)
-				c.events = c.events[:len(c.events)-int(n)]
-				atomic.AddInt32(&c.eventCounter, -n)
-			}
-
-		case <-ticker.C:
-			if len(c.events) > 0 {
-				n, _ := c.send(c.events)
-				c.events = c.events[:len(c.events)-int(n)]
-				atomic.AddInt32(&c.eventCounter, -n)
-			}
-		case <-c.getDoneChan():
-			if len(c.events) > 0 {
-				n, _ := c.send(c.events)
-				c.events = c.events[:len(c.events)-int(n)]
-				atomic.AddInt32(&c.eventCounter, -n)
-			}
+		case <-wake:
+			c.receive()
+		case <-c.schedule(timer):
+			c.flush()
+		case done := <-flush:
+			c.receive()
+			done <- c.flush()
+		case <-done:
+			c.drain()
 			return ErrClientClosed
 		}
 	}
 }
 
+// receive moves reported Events into the buffer of the Start loop.
+// The buffer is submitted as soon as it holds MinBatchSize Events.
+func (c *Client) receive() {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.buffered = len(c.events) + len(pending)
+	c.mu.Unlock()
+
+	c.events = append(c.events, pending...)
+
+	if len(c.events) >= c.MinBatchSize && !time.Now().Before(c.retryAt) {
+		c.flush()
+	}
+}
+
+// schedule arms timer to fire when the oldest buffered Event has waited BatchWait,
+// or when a failed submission may be retried.
+// It returns nil when no Events are buffered.
+func (c *Client) schedule(timer *time.Timer) <-chan time.Time {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+
+	if len(c.events) == 0 {
+		return nil
+	}
+
+	at := c.events[0].queuedAt.Add(c.BatchWait)
+	if at.Before(c.retryAt) {
+		at = c.retryAt
+	}
+
+	timer.Reset(time.Until(at))
+	return timer.C
+}
+
+// drain sends all remaining Events in steps of SendTimeout until none are left
+// or Shutdown gives up.
+func (c *Client) drain() {
+	c.receive()
+
+	for len(c.events) > 0 {
+		select {
+		case <-c.abortChan:
+			c.errHandler.Err(c.events.cleanEvents(), ErrClientClosed)
+			c.events.resolve(ErrClientClosed)
+			c.events = c.events[:0]
+			c.setBuffered()
+			return
+		default:
+		}
+
+		err := c.flush()
+		if err != ErrCircuitOpen {
+			continue
+		}
+
+		// wait for the circuit breaker to allow a probe
+		select {
+		case <-c.abortChan:
+		case <-time.After(time.Until(c.breaker.until())):
+		}
+	}
+}
+
+// flush sends all buffered Events.
+// Events that could not be sent within SendTimeout remain buffered.
+func (c *Client) flush() error {
+	if len(c.events) == 0 {
+		return nil
+	}
+
+	n, err := c.send(c.events)
+	c.events = append(c.events[:0], c.events[n:]...)
+	c.setBuffered()
+
+	if err != nil {
+		c.retryAt = time.Now().Add(c.BatchWait)
+	} else {
+		c.retryAt = time.Time{}
+	}
+
+	return err
+}
+
+// setBuffered publishes the number of Events held by the Start loop.
+func (c *Client) setBuffered() {
+	c.mu.Lock()
+	c.buffered = len(c.events)
+	c.mu.Unlock()
+}
+
+// bufferFullLocked reports whether n more Events exceed MaxBufferedEvents.
+func (c *Client) bufferFullLocked(n int) bool {
+	max := c.MaxBufferedEvents
+	if max == 0 {
+		max = DefaultMaxBufferedEvents
+	}
+
+	return len(c.pending)+c.buffered+n > max
+}
+
+// waitBuffer blocks until the buffer has room for an Event or ctx is done.
+func (c *Client) waitBuffer(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		full := c.bufferFullLocked(1)
+		c.mu.Unlock()
+
+		if !full {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}
+
+// Flush makes the Client submit all Events reported before calling Flush and waits for completion.
+// Unlike Shutdown the Client keeps running.
+// Events that could not be submitted within SendTimeout remain queued and the error is returned.
+func (c *Client) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	state := c.state
+	flush := c.getFlushChanLocked()
+	closed := c.getDoneChanLocked()
+	c.mu.Unlock()
+
+	switch state {
+	case stateNew:
+		return ErrNotStarted
+	case stateDraining, stateStopped:
+		return ErrClientClosed
+	}
+
+	done := make(chan error, 1)
+
+	select {
+	case flush <- done:
+	case <-closed:
+		return ErrClientClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Report is used to submit an Event to GA.
+// It never blocks, Events are buffered until the Start loop submits them.
+// It returns ErrBufferFull when MaxBufferedEvents are buffered.
 // This can be safely called by multiple go routines.
 func (c *Client) Report(e Event) error {
-	select {
-	case <-c.getDoneChan():
+	return c.ReportAt(e, time.Now())
+}
+
+// ReportAt is used to submit an Event to GA that occurred at an earlier time.
+// The queue time of the Event is calculated from reportedAt when it is sent.
+// This can be safely called by multiple go routines.
+func (c *Client) ReportAt(e Event, reportedAt time.Time) error {
+	return c.report(event{
+		reportedAt: reportedAt,
+		e:          e,
+	})
+}
+
+func (c *Client) report(x event) error {
+	if !c.accepting() {
 		return ErrClientClosed
-	default:
 	}
 
-	x := event{
-		reportedAt: time.Now(),
-		e:          e,
+	x.e = c.process(x.e)
+	if x.e == nil {
+		x.resolve(ErrEventDropped)
+		return nil
+	}
+
+	return c.enqueue(x)
+}
+
+func (c *Client) accepting() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state == stateNew || c.state == stateRunning
+}
+
+// enqueue adds Events to the pending buffer.
+// Events enqueued together form a group, which is always submitted in the same batch.
+// A group that doesn't fit in the buffer is passed to the ErrHandler with ErrBufferFull.
+func (c *Client) enqueue(l ...event) error {
+	c.mu.Lock()
+	if c.state != stateNew && c.state != stateRunning {
+		c.mu.Unlock()
+		return ErrClientClosed
+	}
+
+	if c.bufferFullLocked(len(l)) {
+		c.mu.Unlock()
+
+		events(l).resolve(ErrBufferFull)
+		c.handleErr(events(l).cleanEvents(), ErrBufferFull)
+		return ErrBufferFull
+	}
+
+	now := time.Now()
+	for i := range l {
+		l[i].queuedAt = now
+	}
+
+	if len(l) > 1 {
+		c.groups++
+		for i := range l {
+			l[i].group = c.groups
+		}
+	}
+
+	c.pending = append(c.pending, l...)
+	wake := c.getWakeChanLocked()
+	c.mu.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+		// already awake
 	}
 
-	c.eventChan <- x
 	return nil
 }
 
+// Processor is used to modify Events before they are queued for submission.
+type Processor interface {
+	// Process receives a reported Event and returns the Event to submit.
+	// Returning nil drops the Event.
+	Process(Event) Event
+}
+
+// The ProcessorFunc type is an adapter to allow the use of ordinary functions as Processors.
+// If f is a function with the appropriate signature, ProcessorFunc(f) is a Processor that calls f.
+type ProcessorFunc func(e Event) Event
+
+// Process receives a reported Event and returns the Event to submit.
+// Returning nil drops the Event.
+func (f ProcessorFunc) Process(e Event) Event {
+	return f(e)
+}
+
+// Use adds a Processor to the Client.
+// Processors run in the order they were added, each receiving the result of the previous one.
+func (c *Client) Use(p Processor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.processors = append(c.processors, p)
+}
+
+func (c *Client) process(e Event) Event {
+	c.mu.Lock()
+	processors := c.processors
+	c.mu.Unlock()
+
+	for _, p := range processors {
+		if e == nil {
+			return nil
+		}
+		e = p.Process(e)
+	}
+
+	if e != nil && c.Registry != nil {
+		err := c.Registry.Validate(e)
+		if err != nil {
+			c.handleErr([]Event{e}, err)
+		}
+	}
+
+	return e
+}
+
 // ErrHandler is used to handle errors that occur while submitting to GA.
 type ErrHandler interface {
 	// Err receives the Events that erred and the corresponding error.
@@ -216,31 +650,55 @@ func (c *Client) HandleErr(h ErrHandler) {
 	c.errHandler = h
 }
 
+func (c *Client) handleErr(events []Event, err error) {
+	c.mu.Lock()
+	h := c.errHandler
+	c.mu.Unlock()
+
+	if h != nil {
+		h.Err(events, err)
+	}
+}
+
 func (c *Client) send(events events) (int32, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.SendTimeout)
 	defer cancel()
 
 	var sumN int32
 
-	for len(events) >= 20 {
-		n, err := c.sendBatch(ctx, events[:20])
+	for len(events) > 0 {
+		end := batchEnd(events)
+
+		n, err := c.sendBatch(ctx, events[:end])
 		sumN += n
 		if err != nil {
 			return sumN, err
 		}
 
-		events = events[20:]
+		events = events[end:]
 	}
 
-	if len(events) > 0 {
-		n, err := c.sendBatch(ctx, events)
-		sumN += n
-		if err != nil {
-			return sumN, err
-		}
+	return sumN, nil
+}
+
+// batchEnd returns the length of the next batch of at most 20 Events.
+// Events of the same group are never split over two batches.
+func batchEnd(events events) int {
+	if len(events) <= 20 {
+		return len(events)
 	}
 
-	return sumN, nil
+	end := 20
+	for end > 0 && events[end].group != 0 && events[end].group == events[end-1].group {
+		end--
+	}
+
+	if end == 0 {
+		// a group larger than a batch, which ReportTransaction prevents.
+		return 20
+	}
+
+	return end
 }
 
 func (c *Client) sendBatch(ctx context.Context, events events) (int32, error) {
@@ -251,45 +709,144 @@ func (c *Client) sendBatch(ctx context.Context, events events) (int32, error) {
 		// execute
 	}
 
-	for _, e := range events {
-		if e.reportedAt.IsZero() {
+	var (
+		stale    []Event
+		fresh    int
+		attempts int
+	)
+
+	for i, e := range events {
+		if len(e.e) == 0 {
 			continue
 		}
 
-		qt := fmt.Sprint(time.Since(e.reportedAt).Nanoseconds() / 1e6)
+		var age time.Duration
+		if !e.reportedAt.IsZero() {
+			age = time.Since(e.reportedAt)
+		}
+
+		if age > c.MaxQueueTime {
+			stale = append(stale, e.e)
+			e.resolve(ErrQueueTimeExceeded)
+			// cleared so a retry of this batch skips it
+			events[i].e = nil
+			continue
+		}
+
+		fresh++
+
+		// counted once the breaker allows the batch
+		if events[i].attempts+1 > attempts {
+			attempts = events[i].attempts + 1
+		}
+
+		qt := fmt.Sprint(age.Nanoseconds() / 1e6)
 		if qt != "0" {
 			e.e.Set("qt", qt)
 		}
+		if c.CacheBust && e.e.Get("z") == "" {
+			e.e.Set("z", fmt.Sprint(rand.Int63()))
+		}
+	}
+
+	if len(stale) > 0 {
+		c.errHandler.Err(stale, ErrQueueTimeExceeded)
+	}
+
+	if fresh == 0 {
+		return int32(len(events)), nil
 	}
 
 	buf := bytes.NewBuffer(nil)
 	_, err := events.WriteTo(buf)
 	if err != nil {
-		c.errHandler.Err(events.cleanEvents(), err)
-		return int32(len(events)), nil // can't recover this
+		return c.failBatch(events, c.deliveryError(events, attempts, nil, err)), nil // can't recover this
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.urlStr, buf)
+	if err != nil {
+		return c.failBatch(events, c.deliveryError(events, attempts, nil, err)), nil // can't recover this
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := c.HTTP.Post(c.urlStr, "application/x-www-form-urlencoded", buf)
-	if err != nil && strings.Contains(err.Error(), "net/http: request canceled") || err != nil && strings.Contains(err.Error(), "net/http: timeout") {
+	if !c.breaker.allow(time.Now()) {
+		return 0, ErrCircuitOpen
+	}
+
+	for i := range events {
+		if len(events[i].e) > 0 {
+			events[i].attempts++
+		}
+	}
+
+	resp, err := c.HTTP.Do(req.WithContext(ctx))
+	if err != nil && isTimeout(err) {
+		c.recordDelivery(false)
 		return 0, err
 	}
 	if err != nil {
-		c.errHandler.Err(events.cleanEvents(), err)
-		return int32(len(events)), nil // can't recover this
+		c.recordDelivery(false)
+		return c.failBatch(events, c.deliveryError(events, attempts, nil, err)), nil // can't recover this
 	}
+	defer resp.Body.Close()
+
+	c.recordDelivery(resp.StatusCode < 500)
 
 	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		b, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			err = errors.Wrap(err, ErrGoogleAnalytics.Error())
-			c.errHandler.Err(events.cleanEvents(), err)
-			return int32(len(events)), nil // can't recover this
-		}
-		err = errors.Wrap(fmt.Errorf("code: %d message: %s", resp.StatusCode, strings.TrimSuffix(string(b), "\n")), ErrGoogleAnalytics.Error())
-		c.errHandler.Err(events.cleanEvents(), err)
-		return int32(len(events)), nil // can't recover this
+		return c.failBatch(events, c.deliveryError(events, attempts, resp, nil)), nil // can't recover this
 	}
 
+	events.resolve(nil)
 	return int32(len(events)), nil
 }
+
+// recordDelivery updates the circuit breaker with the outcome of a submission.
+func (c *Client) recordDelivery(ok bool) {
+	if c.breaker.record(time.Now(), ok) {
+		c.errHandler.Err(nil, errors.Wrapf(ErrCircuitOpen, "retrying in %s", c.BreakerCooldown))
+	}
+}
+
+func (c *Client) failBatch(events events, err error) int32 {
+	c.errHandler.Err(events.cleanEvents(), err)
+	events.resolve(err)
+	return int32(len(events))
+}
+
+func (c *Client) deliveryError(events events, attempts int, resp *http.Response, err error) *DeliveryError {
+	e := &DeliveryError{
+		Endpoint: c.urlStr,
+		Attempts: attempts,
+		Events:   events.cleanEvents(),
+		Err:      err,
+	}
+
+	if resp == nil {
+		return e
+	}
+
+	e.StatusCode = resp.StatusCode
+	e.Err = ErrGoogleAnalytics
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		e.Err = err
+	}
+	e.Body = strings.TrimSuffix(string(b), "\n")
+
+	return e
+}
+
+// isTimeout reports whether err is a timeout or cancellation, after which the Events can be submitted again.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return false
+}
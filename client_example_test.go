@@ -40,6 +40,38 @@ func ExampleClient() {
 	}
 }
 
+func ExampleNew() {
+
+	c, err := ga.New(
+		ga.WithTID("UA-12345-1"),
+		ga.WithBatchWait(time.Second*30),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// cancel ctx to gracefully shut down the Client, for example on SIGTERM.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		err := c.Run(ctx)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	c.Report(ga.Event{
+		"foo": "baz",
+	})
+
+	cancel()
+	<-done
+}
+
 func ExampleClient_HTTP() {
 
 	c := &ga.Client{
@@ -116,6 +148,12 @@ func ExampleClient_HandleErr() {
 		// some errs might be Wrapped
 		fmt.Println(errors.Cause(err))
 
+		// failed submissions carry the response of GA
+		var dErr *ga.DeliveryError
+		if errors.As(err, &dErr) {
+			fmt.Println(dErr.StatusCode, dErr.Body, dErr.Attempts)
+		}
+
 		// you could re-report the events here if you consider the error a temporary glitch.
 		if err.Error() == "just a flesh wound" {
 			for _, e := range events {
@@ -179,3 +217,26 @@ func ExampleClient_DefaultHTTPHandler() {
 		return
 	}
 }
+
+func ExampleFromContext() {
+
+	c := &ga.Client{
+		TID: "UA-12345-1",
+	}
+
+	signup := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// reported for the same visitor as the pageview of the Middleware.
+		ga.FromContext(r.Context()).Report(ga.Event{
+			"t":  "event",
+			"ec": "account",
+			"ea": "signup",
+		})
+	})
+
+	m := &ga.Middleware{
+		Client: c,
+		Cookie: "cid",
+	}
+
+	http.Handle("/signup", m.Handler(signup))
+}
@@ -1,7 +1,12 @@
 package ga
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pborman/uuid"
 )
@@ -9,21 +14,231 @@ import (
 // DefaultHTTPHandler attempts to provide a sane default HTTPHandler to report pageview events.
 // For this to work the TID of the Client must be set.
 func (c *Client) DefaultHTTPHandler(h http.Handler) http.Handler {
+	return (&Middleware{Client: c}).Handler(h)
+}
+
+// Middleware reports a pageview Event for every request it handles.
+// For this to work the TID of the Client must be set.
+//
+// The context of every request carries a Tracker for the visitor,
+// handlers can use FromContext to report more Events for the same visitor.
+// When the page path is normalized, the pageview is reported after the handler returns,
+// so the route pattern of the request is known. It is reported before the handler otherwise.
+type Middleware struct {
+	// Client reports the pageviews.
+	Client *Client
+	// Cookie is the name of a cookie used to persist the client id of a visitor.
+	// When empty, the client id of the "_ga" cookie set by analytics.js is used if present,
+	// otherwise every request gets a new client id.
+	Cookie string
+	// Sessions adds session control to the reported pageviews.
+	Sessions *SessionTracker
+	// UserID returns the id of the logged in user making r, which is reported as "uid".
+	UserID func(r *http.Request) (uid string, ok bool)
+	// UserIDKey, if set, derives a stable client id from the user id for logged in users without a client id cookie,
+	// so their pageviews are tied together across devices.
+	// The client id is an HMAC-SHA256 of the user id, so the user id itself is not revealed.
+	UserIDKey []byte
+	// TrustForwarded makes Middleware take the IP address of the visitor from the X-Forwarded-For header.
+	// Only enable this behind a load balancer or reverse proxy that sets the header.
+	TrustForwarded bool
+	// EndSession reports whether the pageview for r ends the session of the visitor, for example on logout.
+	// It is only used when Sessions is set.
+	EndSession func(r *http.Request) bool
+	// CampaignParams maps query parameters to Event parameters, in addition to DefaultCampaignParams.
+	// Mapping a default query parameter to "" stops it from being reported.
+	CampaignParams map[string]string
+	// StripCampaign removes the campaign query parameters from the reported page path ("dp").
+	StripCampaign bool
+	// Linker makes Middleware adopt the client id of a valid "_gl" parameter, as added to links
+	// across domains by analytics.js and gtag.js, and removes the parameter from the reported page path.
+	// See DecorateURL to add the parameter to URLs generated on the server.
+	Linker bool
+	// Referrers classifies the referrer ("dr") of pageviews.
+	// Internal referrers are dropped, search engines and social networks set the campaign source and medium.
+	// When nil, the referrer is reported as is.
+	Referrers *ReferrerClassifier
+	// RoutePatterns reports the pattern http.ServeMux matched for the request as page path ("dp"),
+	// so "/users/8123" is reported as "/users/{id}". The method and host of the pattern are left out.
+	// Requests without a pattern are reported with their request URI.
+	// Requests only carry their pattern since Go 1.23, with older versions all requests are reported with their request URI.
+	// The pageview is reported after the handler, so it follows the Events the handler reports.
+	RoutePatterns bool
+	// Normalizer, if set, returns the page path reported for the request.
+	// It takes precedence over RoutePatterns, see RegexpNormalizer.
+	// The pageview is reported after the handler, so it follows the Events the handler reports.
+	Normalizer PathNormalizer
+	// RawPathDimension is the index of a custom dimension that holds the request URI
+	// when the page path is normalized. Zero disables it.
+	RawPathDimension int
+	// ContentGroups sets the content groups ("cg1".."cg5") to the leading segments of the page path,
+	// "/users/{id}/orders" is in the groups "/users", "/users/{id}" and "/users/{id}/orders".
+	ContentGroups bool
+}
+
+// Handler wraps h to report a pageview for every request.
+func (m *Middleware) Handler(h http.Handler) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		e := Event{
-			"tid": c.TID,
-			"cid": uuid.New(),
-			"t":   "pageview",
-			"v":   "1",
-			"dh":  r.Host,
-			"dp":  r.URL.RequestURI(),
-			"dr":  r.Referer(),
-			"ua":  r.UserAgent(),
+		var uid string
+		if m.UserID != nil {
+			if id, ok := m.UserID(r); ok {
+				uid = id
+			}
+		}
+
+		t := &Tracker{
+			client:   m.Client,
+			sessions: m.Sessions,
+			base: Event{
+				"v":   "1",
+				"tid": m.Client.TID,
+				"cid": m.clientID(w, r, uid),
+				"uid": uid,
+				"uip": remoteIP(r, m.TrustForwarded),
+				"ua":  r.UserAgent(),
+			},
+		}
+
+		e := campaign(r.URL, m.CampaignParams)
+		e.Set("t", "pageview")
+		e.Set("dh", r.Host)
+		e.Set("dp", r.URL.RequestURI())
+		e.Set("dr", r.Referer())
+
+		if m.StripCampaign || m.Linker {
+			e.Set("dp", stripQuery(r.URL, m.stripParam))
+		}
+
+		if m.Referrers != nil {
+			m.Referrers.apply(e, r.Host)
+		}
+
+		if m.Sessions != nil && m.EndSession != nil && m.EndSession(r) {
+			e.Set("sc", "end")
 		}
 
-		c.Report(e)
+		r = r.WithContext(NewContext(r.Context(), t))
+
+		if m.Normalizer == nil && !m.RoutePatterns {
+			m.normalize(e, r)
+			t.Report(e)
+
+			h.ServeHTTP(w, r)
+			return
+		}
 
 		h.ServeHTTP(w, r)
+
+		m.normalize(e, r)
+		t.Report(e)
 	})
 }
+
+// normalize sets the normalized page path of r on the pageview e.
+func (m *Middleware) normalize(e Event, r *http.Request) {
+	var p string
+	switch {
+	case m.Normalizer != nil:
+		p = m.Normalizer.Normalize(r)
+	case m.RoutePatterns:
+		p = routePattern(r)
+	}
+
+	if p != "" && p != e.Get("dp") {
+		if m.RawPathDimension > 0 {
+			e.Set("cd"+strconv.Itoa(m.RawPathDimension), e.Get("dp"))
+		}
+		e.Set("dp", p)
+	}
+
+	if m.ContentGroups {
+		setContentGroups(e, e.Get("dp"))
+	}
+}
+
+// stripParam reports whether the query parameter key is removed from the reported page path.
+func (m *Middleware) stripParam(key string) bool {
+	if m.Linker && key == linkerParam {
+		return true
+	}
+	if m.StripCampaign {
+		_, ok := campaignParam(m.CampaignParams, key)
+		return ok
+	}
+	return false
+}
+
+// clientID returns the client id of the visitor making r.
+// A new or adopted client id is persisted in Cookie, if set.
+func (m *Middleware) clientID(w http.ResponseWriter, r *http.Request, uid string) string {
+	if m.Linker {
+		if cid := linkerClientID(r, time.Now()); cid != "" {
+			m.setCookie(w, cid)
+			return cid
+		}
+	}
+
+	if m.Cookie != "" {
+		if c, err := r.Cookie(m.Cookie); err == nil && c.Value != "" {
+			return c.Value
+		}
+	}
+
+	if cid := gaCookieClientID(r); cid != "" {
+		return cid
+	}
+
+	var cid string
+	if uid != "" && len(m.UserIDKey) > 0 {
+		cid = userClientID(m.UserIDKey, uid)
+	} else {
+		cid = uuid.New()
+	}
+
+	m.setCookie(w, cid)
+
+	return cid
+}
+
+// setCookie persists cid in Cookie, if set.
+func (m *Middleware) setCookie(w http.ResponseWriter, cid string) {
+	if m.Cookie != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     m.Cookie,
+			Value:    cid,
+			Path:     "/",
+			MaxAge:   60 * 60 * 24 * 365 * 2,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+}
+
+// gaCookieClientID returns the client id stored in the "_ga" cookie of analytics.js.
+// The cookie value has the form "GA1.2.1234567890.1234567890", the client id is "1234567890.1234567890".
+func gaCookieClientID(r *http.Request) string {
+	c, err := r.Cookie("_ga")
+	if err != nil {
+		return ""
+	}
+
+	parts := strings.SplitN(c.Value, ".", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "GA") {
+		return ""
+	}
+
+	return parts[2]
+}
+
+// userClientID derives a client id from uid, formatted as a UUID.
+func userClientID(key []byte, uid string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(uid))
+	b := mac.Sum(nil)[:16]
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+
+	return uuid.UUID(b).String()
+}
@@ -0,0 +1,174 @@
+package ga
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func Test_Middleware(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+
+	m := &Middleware{
+		Client:   c,
+		Cookie:   "cid",
+		Sessions: &SessionTracker{},
+		EndSession: func(r *http.Request) bool {
+			return r.URL.Path == "/logout"
+		},
+	}
+
+	var served int
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+	}))
+
+	r := httptest.NewRequest("GET", "/foo?bar=baz", nil)
+	r.Header.Set("Referer", "https://example.com/")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "cid" || cookies[0].Value == "" {
+		t.Fatal(cookies)
+	}
+	cid := cookies[0].Value
+
+	for _, path := range []string{"/bar", "/logout"} {
+		r = httptest.NewRequest("GET", path, nil)
+		r.AddCookie(cookies[0])
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if len(w.Result().Cookies()) != 0 {
+			t.Fatal(w.Result().Cookies())
+		}
+	}
+
+	if served != 3 || len(c.pending) != 3 {
+		t.Fatal(served, len(c.pending))
+	}
+
+	e := c.pending[0].e
+	if e.Get("tid") != "UA-12345-1" || e.Get("cid") != cid || e.Get("t") != "pageview" || e.Get("dp") != "/foo?bar=baz" || e.Get("dr") != "https://example.com/" || e.Get("sc") != "start" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[1].e
+	if e.Get("cid") != cid || e.Get("dp") != "/bar" || e.Get("sc") != "" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[2].e
+	if e.Get("cid") != cid || e.Get("sc") != "end" {
+		t.Fatal(e)
+	}
+
+}
+
+func Test_Middleware_GA_Cookie(t *testing.T) {
+
+	c := &Client{}
+
+	h := c.DefaultHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: "_ga", Value: "GA1.2.1234567890.1500000000"})
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	r = httptest.NewRequest("GET", "/", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if c.pending[0].e.Get("cid") != "1234567890.1500000000" {
+		t.Fatal(c.pending[0].e)
+	}
+
+	if cid := c.pending[1].e.Get("cid"); cid == "" || cid == "1234567890.1500000000" {
+		t.Fatal(cid)
+	}
+
+}
+
+func Test_Middleware_UserID(t *testing.T) {
+
+	c := &Client{}
+
+	m := &Middleware{
+		Client:    c,
+		UserIDKey: []byte("secret"),
+		UserID: func(r *http.Request) (string, bool) {
+			uid := r.Header.Get("X-User")
+			return uid, uid != ""
+		},
+	}
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, user := range []string{"alice", "alice", "bob", ""} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-User", user)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	a1, a2, b, anon := c.pending[0].e, c.pending[1].e, c.pending[2].e, c.pending[3].e
+
+	if a1.Get("uid") != "alice" || b.Get("uid") != "bob" || anon.Get("uid") != "" {
+		t.Fatal(a1, b, anon)
+	}
+
+	if a1.Get("cid") != a2.Get("cid") || a1.Get("cid") == b.Get("cid") {
+		t.Fatal(a1.Get("cid"), a2.Get("cid"), b.Get("cid"))
+	}
+
+	if match, _ := regexp.MatchString("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", a1.Get("cid")); !match {
+		t.Fatal(a1.Get("cid"))
+	}
+
+	// an existing cookie takes precedence over the derived client id
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-User", "alice")
+	r.AddCookie(&http.Cookie{Name: "_ga", Value: "GA1.2.1234567890.1500000000"})
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if e := c.pending[4].e; e.Get("cid") != "1234567890.1500000000" || e.Get("uid") != "alice" {
+		t.Fatal(e)
+	}
+
+}
+
+func Test_Middleware_Campaign(t *testing.T) {
+
+	c := &Client{}
+
+	m := &Middleware{
+		Client:         c,
+		CampaignParams: map[string]string{"ref": "cs", "utm_id": ""},
+	}
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	uri := "/landing?b=2&utm_source=newsletter&utm_medium=email&utm_campaign=spring%20sale&utm_term=shoes&utm_content=banner&utm_id=42&gclid=abc&a=1"
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", uri, nil))
+
+	m.StripCampaign = true
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", uri, nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/landing?ref=partner&dclid=xyz", nil))
+
+	e := c.pending[0].e
+	if e.Get("dp") != uri || e.Get("cs") != "newsletter" || e.Get("cm") != "email" || e.Get("cn") != "spring sale" || e.Get("ck") != "shoes" || e.Get("cc") != "banner" || e.Get("ci") != "" || e.Get("gclid") != "abc" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[1].e
+	if e.Get("dp") != "/landing?b=2&utm_id=42&a=1" || e.Get("cs") != "newsletter" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[2].e
+	if e.Get("dp") != "/landing" || e.Get("cs") != "partner" || e.Get("dclid") != "xyz" {
+		t.Fatal(e)
+	}
+
+}
@@ -417,3 +417,583 @@ RESULT_LOOP:
 	}
 
 }
+
+func Test_Zero_Client_ReportAt(t *testing.T) {
+
+	reqChan := make(chan string, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Hour * 100,
+	}
+
+	c.urlStr = ts.URL
+
+	go func() {
+		err := c.Start()
+		if err != nil && err != ErrClientClosed {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+
+	err := c.ReportAt(Event{
+		"foo": "baz",
+	}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case req := <-reqChan:
+		if match, _ := regexp.MatchString("^foo=baz&qt=60\\d\\d\\d$", req); !match {
+			t.Fatal(req)
+		}
+	case <-time.After(time.Millisecond * 500):
+		t.Fatal("expected req")
+	}
+
+}
+
+func Test_Client_CacheBust(t *testing.T) {
+
+	reqChan := make(chan string, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Hour,
+		CacheBust: true,
+	}
+
+	c.urlStr = ts.URL
+
+	go c.Start()
+
+	time.Sleep(time.Millisecond * 10)
+
+	err := c.ReportAt(Event{"foo": "earlier"}, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Report(Event{"foo": "now", "z": "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-reqChan
+	if match, _ := regexp.MatchString("^foo=earlier&qt=\\d+&z=\\d+\nfoo=now&(qt=\\d+&)?z=123$", req); !match {
+		t.Fatal(req)
+	}
+
+	err = c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Client_SendBatch_MaxQueueTime(t *testing.T) {
+
+	reqChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		HTTP:         http.DefaultClient,
+		MaxQueueTime: time.Hour,
+		CacheBust:    true,
+		urlStr:       ts.URL,
+	}
+
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		if len(e) != 1 || e[0].Get("foo") != "stale" {
+			t.Error(e)
+		}
+		errChan <- err
+	}))
+
+	l := events{
+		event{reportedAt: time.Now().Add(-time.Hour * 2), e: Event{"foo": "stale"}},
+		event{e: Event{"foo": "fresh"}},
+	}
+
+	n, err := c.sendBatch(context.Background(), l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatal(n)
+	}
+
+	select {
+	case err := <-errChan:
+		if err != ErrQueueTimeExceeded {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("expected err")
+	}
+
+	select {
+	case req := <-reqChan:
+		if match, _ := regexp.MatchString("^foo=fresh&z=\\d+$", req); !match {
+			t.Fatal(req)
+		}
+	default:
+		t.Fatal("expected req")
+	}
+
+	// a retry of the same batch does not report the stale Event again
+	n, err = c.sendBatch(context.Background(), l[:1])
+	if err != nil || n != 1 {
+		t.Fatal(n, err)
+	}
+
+	select {
+	case err := <-errChan:
+		t.Fatal(err)
+	case req := <-reqChan:
+		t.Fatal(req)
+	default:
+	}
+
+}
+
+func Test_Client_SendBatch_DeliveryError(t *testing.T) {
+
+	errChan := make(chan error, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad", 400)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		HTTP:         http.DefaultClient,
+		MaxQueueTime: time.Hour,
+		urlStr:       ts.URL,
+	}
+
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		if len(e) != 2 || e[0].Get("foo") != "a" || e[1].Get("foo") != "b" {
+			t.Error(e)
+		}
+		errChan <- err
+	}))
+
+	l := events{
+		event{e: Event{"foo": "a"}},
+		event{e: Event{"foo": "b"}, attempts: 2},
+	}
+
+	_, err := c.sendBatch(context.Background(), l)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errChan:
+		dErr, ok := err.(*DeliveryError)
+		if !ok {
+			t.Fatal(err)
+		}
+		if dErr.StatusCode != 400 || dErr.Body != "bad" || dErr.Endpoint != ts.URL || dErr.Attempts != 3 || len(dErr.Events) != 2 {
+			t.Fatal(dErr)
+		}
+		if !errors.Is(err, ErrGoogleAnalytics) {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("expected err")
+	}
+
+}
+
+func Test_Zero_Client_Flush(t *testing.T) {
+
+	reqChan := make(chan string, 2)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Hour * 100,
+	}
+
+	c.urlStr = ts.URL
+
+	go func() {
+		err := c.Start()
+		if err != nil && err != ErrClientClosed {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+
+	for i := 0; i < 25; i++ {
+		err := c.Report(Event{
+			"foo": fmt.Sprintf("%d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := c.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-reqChan:
+		default:
+			t.Fatal("expected req")
+		}
+	}
+
+	err = c.Report(Event{
+		"foo": "baz",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case req := <-reqChan:
+		if match, _ := regexp.MatchString("^foo=baz(&qt=\\d*)?$", req); !match {
+			t.Fatal(req)
+		}
+	default:
+		t.Fatal("expected req")
+	}
+
+	err = c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Flush(context.Background())
+	if err != ErrClientClosed {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Client_Lifecycle(t *testing.T) {
+
+	reqChan := make(chan string, 10)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Hour * 100,
+	}
+
+	c.urlStr = ts.URL
+
+	// buffered before Start
+	err := c.Report(Event{"foo": "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Flush(context.Background())
+	if err != ErrNotStarted {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		startErr := make(chan error, 1)
+		go func() {
+			startErr <- c.Start()
+		}()
+
+		time.Sleep(time.Millisecond * 10)
+
+		err = c.Start()
+		if err != ErrAlreadyStarted {
+			t.Fatal(err)
+		}
+
+		err = c.Report(Event{"foo": fmt.Sprintf("run%d", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = c.Shutdown(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = <-startErr
+		if err != ErrClientClosed {
+			t.Fatal(err)
+		}
+
+		err = c.Report(Event{"foo": "stopped"})
+		if err != ErrClientClosed {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"^foo=new&qt=\\d+\\nfoo=run0(&qt=\\d+)?$", "^foo=run1(&qt=\\d+)?$"}
+	for _, e := range expected {
+		select {
+		case req := <-reqChan:
+			if match, _ := regexp.MatchString(e, req); !match {
+				t.Fatal(req)
+			}
+		default:
+			t.Fatal("expected req")
+		}
+	}
+
+}
+
+func Test_Client_Report_Shutdown_Race(t *testing.T) {
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(200)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{}
+
+	c.urlStr = ts.URL
+
+	go c.Start()
+
+	time.Sleep(time.Millisecond * 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				err := c.Report(Event{"foo": "baz"})
+				if err != nil && err != ErrClientClosed {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+
+	err := c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+
+}
+
+func Test_Client_Shutdown_Timeout(t *testing.T) {
+
+	errChan := make(chan error, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond * 100)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		SendTimeout: time.Millisecond * 20,
+	}
+
+	c.urlStr = ts.URL
+
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		errChan <- err
+	}))
+
+	go c.Start()
+
+	time.Sleep(time.Millisecond * 10)
+
+	a, err := c.ReportWithAck(Event{"foo": "baz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	err = c.Shutdown(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+	if a.Err() != ErrClientClosed {
+		t.Fatal(a.Err())
+	}
+
+	select {
+	case err := <-errChan:
+		if err != ErrClientClosed {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("expected err")
+	}
+
+}
+
+func Test_Client_Adaptive_Batching(t *testing.T) {
+
+	type req struct {
+		body string
+		at   time.Time
+	}
+
+	reqChan := make(chan req, 10)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- req{body: string(b), at: time.Now()}
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait:    time.Millisecond * 100,
+		MinBatchSize: 3,
+	}
+
+	c.urlStr = ts.URL
+
+	go c.Start()
+
+	time.Sleep(time.Millisecond * 10)
+
+	// sent once the oldest Event waited BatchWait
+	start := time.Now()
+	c.Report(Event{"foo": "a"})
+	time.Sleep(time.Millisecond * 50)
+	c.Report(Event{"foo": "b"})
+
+	select {
+	case r := <-reqChan:
+		if match, _ := regexp.MatchString("^foo=a&qt=\\d+\\nfoo=b&qt=\\d+$", r.body); !match {
+			t.Fatal(r.body)
+		}
+		if d := r.at.Sub(start); d < time.Millisecond*100 || d > time.Millisecond*300 {
+			t.Fatal(d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected req")
+	}
+
+	// sent immediately once MinBatchSize Events are buffered
+	start = time.Now()
+	c.Report(Event{"foo": "c"})
+	c.Report(Event{"foo": "d"})
+	c.Report(Event{"foo": "e"})
+
+	select {
+	case r := <-reqChan:
+		if d := r.at.Sub(start); d > time.Millisecond*50 {
+			t.Fatal(d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected req")
+	}
+
+	// idle
+	select {
+	case r := <-reqChan:
+		t.Fatal(r.body)
+	case <-time.After(time.Millisecond * 200):
+	}
+
+	err := c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Client_Schedule_Idle(t *testing.T) {
+
+	c := &Client{BatchWait: time.Millisecond}
+
+	timer := time.NewTimer(time.Hour)
+
+	if c.schedule(timer) != nil {
+		t.Fatal("expected idle")
+	}
+
+	c.events = events{event{queuedAt: time.Now(), e: Event{"foo": "baz"}}}
+	c.retryAt = time.Now().Add(time.Millisecond * 50)
+
+	start := time.Now()
+	<-c.schedule(timer)
+	if time.Since(start) < time.Millisecond*50 {
+		t.Fatal("expected retry delay")
+	}
+
+}
@@ -0,0 +1,86 @@
+// Command gaproxy serves a first party Google Analytics collection endpoint.
+//
+// Browsers send hits to /collect and /batch on the domain gaproxy runs on,
+// gaproxy enriches them with the IP address and user agent of the browser
+// and forwards them to Google Analytics in batches.
+//
+//	gaproxy -addr :8080 -trust-forwarded
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/romainmenke/ga"
+)
+
+func main() {
+	var (
+		addr           = flag.String("addr", ":8080", "address to listen on")
+		batchWait      = flag.Duration("batch-wait", time.Second*15, "how long hits are batched before they are sent")
+		trustForwarded = flag.Bool("trust-forwarded", false, "take the client IP address from X-Forwarded-For")
+	)
+	flag.Parse()
+
+	c, err := ga.New(
+		ga.WithBatchWait(*batchWait),
+		ga.WithErrHandler(ga.ErrHandlerFunc(func(events []ga.Event, err error) {
+			log.Printf("gaproxy: %d hits failed: %s", len(events), err)
+		})),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the Client is shut down after the server, so in-flight requests can still report hits.
+	clientCtx, cancelClient := context.WithCancel(context.Background())
+	clientDone := make(chan error, 1)
+	go func() {
+		clientDone <- c.Run(clientCtx)
+	}()
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: &ga.Proxy{
+			Client:         c,
+			TrustForwarded: *trustForwarded,
+		},
+		ReadTimeout:  time.Second * 5,
+		WriteTimeout: time.Second * 5,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		defer cancel()
+
+		err := srv.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+
+	log.Printf("gaproxy: listening on %s", *addr)
+
+	err = srv.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+
+	cancelClient()
+
+	err = <-clientDone
+	if err != nil {
+		log.Fatal(err)
+	}
+}
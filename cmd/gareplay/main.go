@@ -0,0 +1,80 @@
+// Command gareplay reports archived hits to Google Analytics.
+//
+// It reads Hits as JSON Lines, as written by ga.HitEncoder, from the files
+// given as arguments or from stdin. Hits older than the maximum queue time
+// are dropped and logged.
+//
+//	gareplay -rate 50 archive-1.jsonl archive-2.jsonl
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/romainmenke/ga"
+)
+
+func main() {
+	var (
+		rate         = flag.Int("rate", 20, "maximum number of hits reported per second, zero means no limit")
+		maxQueueTime = flag.Duration("max-queue-time", ga.DefaultMaxQueueTime, "drop hits reported longer ago than this")
+	)
+	flag.Parse()
+
+	c, err := ga.New(
+		ga.WithBatchWait(time.Second),
+		ga.WithErrHandler(ga.ErrHandlerFunc(func(events []ga.Event, err error) {
+			log.Printf("gareplay: %d hits failed: %s", len(events), err)
+		})),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	clientCtx, cancelClient := context.WithCancel(context.Background())
+	clientDone := make(chan error, 1)
+	go func() {
+		clientDone <- c.Run(clientCtx)
+	}()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	p := &ga.Replayer{
+		Client:       c,
+		MaxQueueTime: *maxQueueTime,
+		Rate:         *rate,
+	}
+
+	var readers []io.Reader
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	if len(readers) == 0 {
+		readers = append(readers, os.Stdin)
+	}
+
+	stats, err := p.Replay(ctx, io.MultiReader(readers...))
+	if err != nil {
+		log.Println(err)
+	}
+
+	log.Printf("gareplay: reported %d hits, dropped %d hits", stats.Reported, stats.Dropped)
+
+	cancelClient()
+
+	err = <-clientDone
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,368 @@
+package ga
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Product actions of Enhanced Ecommerce.
+const (
+	ActionDetail         = "detail"
+	ActionClick          = "click"
+	ActionAdd            = "add"
+	ActionRemove         = "remove"
+	ActionCheckout       = "checkout"
+	ActionCheckoutOption = "checkout_option"
+	ActionPurchase       = "purchase"
+	ActionRefund         = "refund"
+)
+
+// maxIndex is the highest index GA accepts for products, impression lists and promotions.
+const maxIndex = 200
+
+// Product is a product in an Enhanced Ecommerce hit.
+// Zero fields are omitted.
+type Product struct {
+	ID       string
+	Name     string
+	Brand    string
+	Category string
+	Variant  string
+	Coupon   string
+	Price    float64
+	Quantity int
+	Position int
+	// Product scoped custom dimensions by index.
+	Dimensions map[int]string
+	// Product scoped custom metrics by index.
+	Metrics map[int]string
+}
+
+// ProductAction is a product action with the products it applies to.
+// The transaction fields are used with ActionPurchase and ActionRefund.
+type ProductAction struct {
+	// Action is one of the Action constants.
+	Action string
+	// List is the product list the action happened in.
+	List           string
+	TransactionID  string
+	Affiliation    string
+	Revenue        float64
+	Tax            float64
+	Shipping       float64
+	Coupon         string
+	CheckoutStep   int
+	CheckoutOption string
+	Products       []Product
+}
+
+// ImpressionList is a named list of products the visitor saw.
+type ImpressionList struct {
+	Name     string
+	Products []Product
+}
+
+// Promotion is an internal promotion the visitor saw or clicked.
+type Promotion struct {
+	ID       string
+	Name     string
+	Creative string
+	Position string
+}
+
+// Ecommerce builds Enhanced Ecommerce Events.
+// It computes the indexed parameter names ("pr1id", "il1pi2nm", "promo1id", ...)
+// and splits the data over multiple Events when a single Event would exceed the size limits of GA.
+type Ecommerce struct {
+	Action      *ProductAction
+	Impressions []ImpressionList
+	Promotions  []Promotion
+	// PromotionClick reports the Promotions as clicked instead of viewed.
+	PromotionClick bool
+}
+
+// Events returns copies of base carrying the ecommerce data.
+// Most data fits in a single Event. When it doesn't, the data is split over multiple Events,
+// each carrying the product action, while the transaction totals (revenue, tax, shipping and coupon)
+// are only set on the first.
+//
+// Purchases are the exception: GA counts every hit with a purchase action as a transaction
+// and ignores products on hits without a product action, so the products of a purchase are never split.
+// The first Event carries the purchase and all its products, impressions and promotions may follow in others.
+// Use Validate to check that a purchase fits in a single hit.
+func (ec Ecommerce) Events(base Event) []Event {
+	b := &ecBuilder{base: base}
+
+	if ec.Action != nil {
+		a := ec.Action
+		b.shared = Event{
+			"pa":  a.Action,
+			"pal": a.List,
+			"ti":  a.TransactionID,
+			"ta":  a.Affiliation,
+			"col": a.CheckoutOption,
+		}
+		if a.CheckoutStep > 0 {
+			b.shared.Set("cos", strconv.Itoa(a.CheckoutStep))
+		}
+		b.first = Event{
+			"tr":  formatFloat(a.Revenue),
+			"tt":  formatFloat(a.Tax),
+			"ts":  formatFloat(a.Shipping),
+			"tcc": a.Coupon,
+		}
+		if a.Action == ActionPurchase {
+			for k, v := range b.shared {
+				b.first.Set(k, v)
+			}
+			b.shared = Event{}
+		}
+	}
+
+	if ec.PromotionClick && len(ec.Promotions) > 0 {
+		if b.shared == nil {
+			b.shared = Event{}
+		}
+		b.shared.Set("promoa", "click")
+	}
+
+	b.next()
+
+	if ec.Action != nil {
+		purchase := ec.Action.Action == ActionPurchase
+
+		for _, p := range ec.Action.Products {
+			p := p
+			try := func(h *ecHit) (Event, bool) {
+				i := h.products + 1
+				if i > maxIndex {
+					return nil, false
+				}
+				return p.fields("pr" + strconv.Itoa(i)), true
+			}
+			commit := func(h *ecHit) {
+				h.products++
+			}
+
+			if !purchase {
+				b.add(try, commit)
+				continue
+			}
+
+			// the products of a purchase stay on the first Event, see Validate
+			if fields, ok := try(b.cur); ok {
+				b.commit(fields, commit)
+			}
+		}
+	}
+
+	for _, l := range ec.Impressions {
+		l := l
+		for _, p := range l.Products {
+			p := p
+			b.add(func(h *ecHit) (Event, bool) {
+				li, ok := h.lists[l.Name]
+				if !ok {
+					li = len(h.listProducts) + 1
+				}
+				if li > maxIndex {
+					return nil, false
+				}
+
+				pi := 1
+				if ok {
+					pi = h.listProducts[li-1] + 1
+				}
+				if pi > maxIndex {
+					return nil, false
+				}
+
+				prefix := "il" + strconv.Itoa(li)
+				fields := p.fields(prefix + "pi" + strconv.Itoa(pi))
+				if !ok && l.Name != "" {
+					fields.Set(prefix+"nm", l.Name)
+				}
+				return fields, true
+			}, func(h *ecHit) {
+				li, ok := h.lists[l.Name]
+				if !ok {
+					h.listProducts = append(h.listProducts, 0)
+					li = len(h.listProducts)
+					h.lists[l.Name] = li
+				}
+				h.listProducts[li-1]++
+			})
+		}
+	}
+
+	for _, p := range ec.Promotions {
+		p := p
+		b.add(func(h *ecHit) (Event, bool) {
+			i := h.promotions + 1
+			if i > maxIndex {
+				return nil, false
+			}
+			prefix := "promo" + strconv.Itoa(i)
+			return Event{
+				prefix + "id": p.ID,
+				prefix + "nm": p.Name,
+				prefix + "cr": p.Creative,
+				prefix + "ps": p.Position,
+			}, true
+		}, func(h *ecHit) {
+			h.promotions++
+		})
+	}
+
+	return b.hits()
+}
+
+// Validate checks that GA accepts the Events of ec for base.
+// It returns ErrInvalidTransaction for a purchase that does not fit in a single hit,
+// because it has more products than GA accepts or exceeds the size limit of a hit.
+func (ec Ecommerce) Validate(base Event) error {
+	if ec.Action == nil || ec.Action.Action != ActionPurchase {
+		return nil
+	}
+
+	if n := len(ec.Action.Products); n > maxIndex {
+		return errors.Wrapf(ErrInvalidTransaction, "%d products, the maximum is %d", n, maxIndex)
+	}
+
+	if size := encodedSize(ec.Events(base)[0]); size > maxHitSize {
+		return errors.Wrapf(ErrInvalidTransaction, "purchase of %d bytes, the maximum is %d", size, maxHitSize)
+	}
+
+	return nil
+}
+
+func (p Product) fields(prefix string) Event {
+	e := Event{
+		prefix + "id": p.ID,
+		prefix + "nm": p.Name,
+		prefix + "br": p.Brand,
+		prefix + "ca": p.Category,
+		prefix + "va": p.Variant,
+		prefix + "cc": p.Coupon,
+		prefix + "pr": formatFloat(p.Price),
+	}
+
+	if p.Quantity != 0 {
+		e.Set(prefix+"qt", strconv.Itoa(p.Quantity))
+	}
+	if p.Position != 0 {
+		e.Set(prefix+"ps", strconv.Itoa(p.Position))
+	}
+
+	for i, v := range p.Dimensions {
+		e.Set(prefix+"cd"+strconv.Itoa(i), v)
+	}
+	for i, v := range p.Metrics {
+		e.Set(prefix+"cm"+strconv.Itoa(i), v)
+	}
+
+	return e
+}
+
+func formatFloat(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// ecHit is an Event under construction together with the indexes used so far.
+type ecHit struct {
+	e            Event
+	size         int
+	products     int
+	promotions   int
+	lists        map[string]int
+	listProducts []int
+}
+
+type ecBuilder struct {
+	base   Event
+	shared Event // set on every Event
+	first  Event // only set on the first Event
+	done   []*ecHit
+	cur    *ecHit
+}
+
+func (b *ecBuilder) next() {
+	h := &ecHit{
+		e:     Event{},
+		lists: map[string]int{},
+	}
+
+	fields := []Event{b.base, b.shared}
+	if b.cur == nil {
+		fields = append(fields, b.first)
+	}
+
+	for _, f := range fields {
+		for k, v := range f {
+			if k != "" && v != "" {
+				h.e.Set(k, v)
+			}
+		}
+	}
+
+	h.size = encodedSize(h.e)
+
+	if b.cur != nil {
+		b.done = append(b.done, b.cur)
+	}
+	b.cur = h
+}
+
+// add adds the fields returned by try to the current Event, or to a new Event if they don't fit.
+func (b *ecBuilder) add(try func(*ecHit) (Event, bool), commit func(*ecHit)) {
+	fields, ok := try(b.cur)
+	if ok && (b.cur.size+encodedSize(fields) <= maxHitSize || b.empty()) {
+		b.commit(fields, commit)
+		return
+	}
+
+	b.next()
+
+	fields, _ = try(b.cur)
+	b.commit(fields, commit)
+}
+
+func (b *ecBuilder) commit(fields Event, commit func(*ecHit)) {
+	for k, v := range fields {
+		if k != "" && v != "" {
+			b.cur.e.Set(k, v)
+		}
+	}
+	b.cur.size += encodedSize(fields)
+	commit(b.cur)
+}
+
+// empty reports whether the current Event carries no products, impressions or promotions yet.
+func (b *ecBuilder) empty() bool {
+	return b.cur.products == 0 && b.cur.promotions == 0 && len(b.cur.listProducts) == 0
+}
+
+func (b *ecBuilder) hits() []Event {
+	l := make([]Event, 0, len(b.done)+1)
+	for _, h := range b.done {
+		l = append(l, h.e)
+	}
+	return append(l, b.cur.e)
+}
+
+// encodedSize returns the number of bytes the pairs of e take when written with Event.WriteTo,
+// counting a separator for every pair.
+func encodedSize(e Event) int {
+	var n int
+	for k, v := range e {
+		if k != "" && v != "" {
+			n += 1 + len(k) + 1 + len(url.QueryEscape(v))
+		}
+	}
+	return n
+}
@@ -0,0 +1,218 @@
+package ga
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Ecommerce_Purchase(t *testing.T) {
+
+	ec := Ecommerce{
+		Action: &ProductAction{
+			Action:        ActionPurchase,
+			TransactionID: "T12345",
+			Revenue:       37.39,
+			Tax:           2.85,
+			Shipping:      5.34,
+			Coupon:        "SUMMER2013",
+			Products: []Product{
+				{ID: "P12345", Name: "Android Warhol T-Shirt", Category: "Apparel", Price: 29.2, Quantity: 2, Dimensions: map[int]string{1: "Member"}},
+				{ID: "P67890", Name: "Sticker", Price: 1.5},
+			},
+		},
+	}
+
+	l := ec.Events(Event{"v": "1", "t": "event"})
+	if len(l) != 1 {
+		t.Fatal(l)
+	}
+
+	expected := Event{
+		"v":      "1",
+		"t":      "event",
+		"pa":     "purchase",
+		"ti":     "T12345",
+		"tr":     "37.39",
+		"tt":     "2.85",
+		"ts":     "5.34",
+		"tcc":    "SUMMER2013",
+		"pr1id":  "P12345",
+		"pr1nm":  "Android Warhol T-Shirt",
+		"pr1ca":  "Apparel",
+		"pr1pr":  "29.2",
+		"pr1qt":  "2",
+		"pr1cd1": "Member",
+		"pr2id":  "P67890",
+		"pr2nm":  "Sticker",
+		"pr2pr":  "1.5",
+	}
+
+	if len(l[0]) != len(expected) {
+		t.Fatal(l[0])
+	}
+	for k, v := range expected {
+		if l[0].Get(k) != v {
+			t.Fatal(k, l[0].Get(k))
+		}
+	}
+
+}
+
+func Test_Ecommerce_Impressions_Promotions(t *testing.T) {
+
+	ec := Ecommerce{
+		Impressions: []ImpressionList{
+			{Name: "Search Results", Products: []Product{{ID: "a", Position: 1}, {ID: "b", Position: 2}}},
+			{Name: "Related", Products: []Product{{ID: "c"}}},
+		},
+		Promotions: []Promotion{
+			{ID: "PROMO_1234", Name: "Summer Sale", Creative: "banner", Position: "top"},
+		},
+		PromotionClick: true,
+	}
+
+	l := ec.Events(Event{"t": "pageview"})
+	if len(l) != 1 {
+		t.Fatal(l)
+	}
+
+	e := l[0]
+
+	expected := map[string]string{
+		"il1nm":    "Search Results",
+		"il1pi1id": "a",
+		"il1pi1ps": "1",
+		"il1pi2id": "b",
+		"il1pi2ps": "2",
+		"il2nm":    "Related",
+		"il2pi1id": "c",
+		"promo1id": "PROMO_1234",
+		"promo1nm": "Summer Sale",
+		"promo1cr": "banner",
+		"promo1ps": "top",
+		"promoa":   "click",
+		"t":        "pageview",
+	}
+
+	if len(e) != len(expected) {
+		t.Fatal(e)
+	}
+	for k, v := range expected {
+		if e.Get(k) != v {
+			t.Fatal(k, e.Get(k))
+		}
+	}
+
+}
+
+func Test_Ecommerce_Split(t *testing.T) {
+
+	var products []Product
+	for i := 0; i < 300; i++ {
+		products = append(products, Product{
+			ID:   strconv.Itoa(i),
+			Name: strings.Repeat("x", 50),
+		})
+	}
+
+	ec := Ecommerce{
+		Action: &ProductAction{
+			Action:        ActionRefund,
+			TransactionID: "T1",
+			Revenue:       100,
+			Products:      products,
+		},
+	}
+
+	l := ec.Events(Event{"t": "event"})
+	if len(l) < 2 {
+		t.Fatal(len(l))
+	}
+
+	var count int
+	for i, e := range l {
+		buf := bytes.NewBuffer(nil)
+		e.WriteTo(buf)
+		if buf.Len() > maxHitSize {
+			t.Fatal(i, buf.Len())
+		}
+
+		if e.Get("pa") != "refund" || e.Get("ti") != "T1" {
+			t.Fatal(i, e)
+		}
+
+		if (i == 0) != (e.Get("tr") == "100") {
+			t.Fatal(i, e.Get("tr"))
+		}
+
+		for j := 1; e.Get("pr"+strconv.Itoa(j)+"id") != ""; j++ {
+			if e.Get("pr"+strconv.Itoa(j)+"id") != strconv.Itoa(count) {
+				t.Fatal(i, j, count)
+			}
+			count++
+		}
+	}
+
+	if count != 300 {
+		t.Fatal(count)
+	}
+
+}
+
+func Test_Ecommerce_Purchase_Split(t *testing.T) {
+
+	products := func(n, nameLen int) []Product {
+		var l []Product
+		for i := 0; i < n; i++ {
+			l = append(l, Product{ID: strconv.Itoa(i), Name: strings.Repeat("x", nameLen)})
+		}
+		return l
+	}
+
+	ec := Ecommerce{
+		Action: &ProductAction{
+			Action:        ActionPurchase,
+			TransactionID: "T1",
+			Revenue:       100,
+			Products:      products(20, 10),
+		},
+		Impressions: []ImpressionList{{Name: "related", Products: products(150, 50)}},
+	}
+
+	err := ec.Validate(Event{"t": "event"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := ec.Events(Event{"t": "event"})
+	if len(l) < 2 {
+		t.Fatal(len(l))
+	}
+
+	// only the first Event counts as a transaction and it carries all products
+	for i, e := range l {
+		if (i == 0) != (e.Get("pa") == "purchase") || (i == 0) != (e.Get("ti") == "T1") {
+			t.Fatal(i, e)
+		}
+		if (i == 0) != (e.Get("pr20id") == "19") || e.Get("pr21id") != "" {
+			t.Fatal(i, e)
+		}
+	}
+
+	ec.Action.Products = products(150, 50)
+	err = ec.Validate(Event{"t": "event"})
+	if errors.Cause(err) != ErrInvalidTransaction {
+		t.Fatal(err)
+	}
+
+	ec.Action.Products = products(201, 0)
+	err = ec.Validate(Event{"t": "event"})
+	if errors.Cause(err) != ErrInvalidTransaction {
+		t.Fatal(err)
+	}
+
+}
@@ -1,5 +1,7 @@
 package ga
 
+import "fmt"
+
 // Error is the ga Error type
 type Error string
 
@@ -14,3 +16,78 @@ const ErrAlreadyStarted = Error("ga client already started")
 
 // ErrGoogleAnalytics occurs when POST calls to Google Analytics fail.
 const ErrGoogleAnalytics = Error("google analytics api error")
+
+// ErrMalformedEvent occurs when a hit can not be decoded into an Event.
+const ErrMalformedEvent = Error("ga malformed event")
+
+// ErrQueueTimeExceeded occurs when an Event was reported longer ago than the maximum queue time.
+// These Events are never submitted to GA.
+const ErrQueueTimeExceeded = Error("ga queue time exceeded")
+
+// DeliveryError describes a batch of Events that could not be submitted to GA.
+// It matches ErrGoogleAnalytics with errors.Is.
+type DeliveryError struct {
+	// StatusCode is the HTTP status code returned by GA, or zero if no response was received.
+	StatusCode int
+	// Body is the response body returned by GA.
+	Body string
+	// Endpoint is the URL the Events were submitted to.
+	Endpoint string
+	// Attempts is the number of times the Events were submitted.
+	Attempts int
+	// Events are the Events that failed.
+	Events []Event
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *DeliveryError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: code: %d message: %s", ErrGoogleAnalytics, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("%s: %s", ErrGoogleAnalytics, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DeliveryError) Unwrap() error { return e.Err }
+
+// Cause returns the underlying error, for use with github.com/pkg/errors.
+func (e *DeliveryError) Cause() error { return e.Err }
+
+// Is reports whether target is ErrGoogleAnalytics.
+func (e *DeliveryError) Is(target error) bool { return target == ErrGoogleAnalytics }
+
+// ErrEventDropped occurs when a Processor drops an Event reported with ReportWithAck or ReportSync.
+const ErrEventDropped = Error("ga event dropped")
+
+// ErrBufferFull occurs when an Event is reported while the Client buffers MaxBufferedEvents.
+// The Event is never submitted.
+const ErrBufferFull = Error("ga buffer full")
+
+// ErrNotStarted occurs when an action requires a running Client, but Client.Start was not called yet.
+const ErrNotStarted = Error("ga client not started")
+
+// ErrInvalidOption occurs when New receives an Option with an invalid value.
+const ErrInvalidOption = Error("ga invalid option")
+
+// ErrCircuitOpen occurs when the circuit breaker of a Client opens after consecutive failed submissions.
+// Events remain buffered while the breaker is open.
+const ErrCircuitOpen = Error("ga circuit breaker open")
+
+// ErrNoTracker occurs when reporting to the Tracker of a context that does not carry one.
+const ErrNoTracker = Error("ga no tracker in context")
+
+// ErrInvalidTransaction occurs when a Transaction or an Ecommerce purchase can not be reported.
+const ErrInvalidTransaction = Error("ga invalid transaction")
+
+// ErrInvalidDefinition occurs when a Registry is created with an invalid custom dimension or metric.
+const ErrInvalidDefinition = Error("ga invalid definition")
+
+// ErrUnknownDefinition occurs when a custom dimension or metric is not in the Registry.
+const ErrUnknownDefinition = Error("ga unknown definition")
+
+// ErrScopeMismatch occurs when a custom dimension or metric is set outside its scope.
+const ErrScopeMismatch = Error("ga scope mismatch")
+
+// ErrInvalidLinker occurs when a "_gl" linker parameter is malformed or was created for another visitor or too long ago.
+const ErrInvalidLinker = Error("ga invalid linker")
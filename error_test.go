@@ -1,6 +1,11 @@
 package ga
 
-import "testing"
+import (
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+)
 
 // contrived, but codecov isn't very smart
 
@@ -18,4 +23,90 @@ func Test_Error(t *testing.T) {
 		t.Fatal()
 	}
 
+	if ErrMalformedEvent.Error() != "ga malformed event" {
+		t.Fatal()
+	}
+
+	if ErrQueueTimeExceeded.Error() != "ga queue time exceeded" {
+		t.Fatal()
+	}
+
+	if ErrNotStarted.Error() != "ga client not started" {
+		t.Fatal()
+	}
+
+	if ErrEventDropped.Error() != "ga event dropped" {
+		t.Fatal()
+	}
+
+	if ErrBufferFull.Error() != "ga buffer full" {
+		t.Fatal()
+	}
+
+	if ErrInvalidOption.Error() != "ga invalid option" {
+		t.Fatal()
+	}
+
+	if ErrCircuitOpen.Error() != "ga circuit breaker open" {
+		t.Fatal()
+	}
+
+	if ErrNoTracker.Error() != "ga no tracker in context" {
+		t.Fatal()
+	}
+
+	if ErrInvalidTransaction.Error() != "ga invalid transaction" {
+		t.Fatal()
+	}
+
+	if ErrInvalidDefinition.Error() != "ga invalid definition" {
+		t.Fatal()
+	}
+
+	if ErrUnknownDefinition.Error() != "ga unknown definition" {
+		t.Fatal()
+	}
+
+	if ErrScopeMismatch.Error() != "ga scope mismatch" {
+		t.Fatal()
+	}
+
+	if ErrInvalidLinker.Error() != "ga invalid linker" {
+		t.Fatal()
+	}
+
+}
+
+func Test_DeliveryError(t *testing.T) {
+
+	var err error = &DeliveryError{
+		StatusCode: 400,
+		Body:       "bad",
+		Endpoint:   "https://www.google-analytics.com/batch",
+		Attempts:   1,
+	}
+
+	if err.Error() != "google analytics api error: code: 400 message: bad" {
+		t.Fatal(err)
+	}
+
+	err = errors.Wrap(&DeliveryError{Err: io.ErrUnexpectedEOF}, "wrapped")
+
+	if !errors.Is(err, ErrGoogleAnalytics) {
+		t.Fatal(err)
+	}
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal(err)
+	}
+
+	if errors.Cause(err) != io.ErrUnexpectedEOF {
+		t.Fatal(err)
+	}
+
+	var dErr *DeliveryError
+	if !errors.As(err, &dErr) || dErr.Err != io.ErrUnexpectedEOF {
+		t.Fatal(err)
+	}
+
 }
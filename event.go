@@ -1,6 +1,8 @@
 package ga
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"net/url"
 	"sort"
@@ -76,21 +78,90 @@ func (e Event) WriteTo(w io.Writer) (int64, error) {
 	return sumN, err
 }
 
+// MarshalJSON encodes Event as a JSON object with its keys in sorted order.
+// Like WriteTo, it omits pairs with an empty key or value.
+func (e Event) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteByte('{')
+
+	// json.Encoder terminates each value with a newline, which is trimmed again.
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	for i, p := range e.sortedKeyValues() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		err := enc.Encode(p.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+
+		err = enc.Encode(p.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object of string values into Event.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var m map[string]string
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+
+	if m == nil {
+		*e = nil
+		return nil
+	}
+
+	*e = Event(m)
+	return nil
+}
+
 type event struct {
 	reportedAt time.Time
+	queuedAt   time.Time
+	attempts   int
+	group      int // non-zero for Events that must be submitted in the same batch.
+	ack        *Ack
 	e          Event
 }
 
+func (e event) resolve(err error) {
+	if e.ack != nil {
+		e.ack.resolve(err)
+	}
+}
+
 type events []event
 
 func (l events) cleanEvents() []Event {
-	x := make([]Event, len(l), len(l))
+	x := make([]Event, 0, len(l))
 	for _, e := range l {
+		if len(e.e) == 0 {
+			continue
+		}
 		x = append(x, e.e)
 	}
 	return x
 }
 
+func (l events) resolve(err error) {
+	for _, e := range l {
+		e.resolve(err)
+	}
+}
+
 // WriteTo formats a batch of Events and writes them to w.
 func (l events) WriteTo(w io.Writer) (int64, error) {
 	ws, ok := w.(writeStringer)
@@ -2,6 +2,7 @@ package ga
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -68,3 +69,30 @@ func Test_Events_WriteTo_WithEscaping(t *testing.T) {
 		t.Fatal(buf.String())
 	}
 }
+
+func Test_Event_JSON(t *testing.T) {
+	e := Event{}
+	e.Set("gamma", "three")
+	e.Set("alpha", "one")
+	e.Set("beta", "two")
+	e.Set("delta", "")
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != `{"alpha":"one","beta":"two","gamma":"three"}` {
+		t.Fatal(string(b))
+	}
+
+	var x Event
+	err = json.Unmarshal(b, &x)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x) != 3 || x.Get("gamma") != "three" {
+		t.Fatal(x)
+	}
+}
@@ -0,0 +1,235 @@
+package ga
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// maxExceptionDescription is the maximum length of an exception description ("exd") GA accepts.
+const maxExceptionDescription = 150
+
+// ReportException is used to submit an exception hit for err to GA.
+// The description ("exd") holds the type of the innermost error wrapped by err and the top stack frame,
+// never the error message, which might contain user data. The stack frame is taken from err if it was created with
+// github.com/pkg/errors, otherwise it is the caller of ReportException.
+func (c *Client) ReportException(err error, fatal bool) error {
+	if err == nil {
+		return nil
+	}
+
+	frame, ok := errorFrame(err)
+	if !ok {
+		frame = callerFrame(2)
+	}
+
+	return c.Report(c.exception(exceptionEvent(exceptionDescription(err, frame), fatal)))
+}
+
+// recoverTimeout is the longest time Recover waits for the exception hit to be submitted.
+const recoverTimeout = time.Second * 2
+
+// Recover reports a panic as a fatal exception hit and panics again.
+// The hit is submitted before panicking again, as the panic usually ends the process,
+// Recover waits at most 2 seconds for it. A Client that was not started only buffers the hit.
+// It must be deferred directly:
+//
+//	go func() {
+//		defer c.Recover()
+//		// ...
+//	}()
+func (c *Client) Recover() {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	a, err := c.ReportWithAck(c.exception(exceptionEvent(exceptionDescription(v, panicFrame()), true)))
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), recoverTimeout)
+		if c.Flush(ctx) == nil {
+			a.Wait(ctx)
+		}
+		cancel()
+	}
+
+	panic(v)
+}
+
+// RecoverHTTPHandler wraps h to report panics as fatal exception hits and respond with 500 Internal Server Error.
+// When the request carries a Tracker, the exception is reported for the visitor.
+func (c *Client) RecoverHTTPHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			e := exceptionEvent(exceptionDescription(v, panicFrame()), true)
+			if t := FromContext(r.Context()); t != nil {
+				t.Report(e)
+			} else {
+				c.Report(c.exception(e))
+			}
+
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// exception sets the exception fields of x on the fields of Events the Client builds itself.
+func (c *Client) exception(x Event) Event {
+	e := c.base()
+	for k, v := range x {
+		e.Set(k, v)
+	}
+	return e
+}
+
+func exceptionEvent(description string, fatal bool) Event {
+	exf := "0"
+	if fatal {
+		exf = "1"
+	}
+
+	return Event{
+		"t":   "exception",
+		"exd": description,
+		"exf": exf,
+	}
+}
+
+// exceptionDescription formats the type of v, unwrapping wrapped errors, and frame, sanitized and truncated to fit in "exd".
+func exceptionDescription(v interface{}, frame runtime.Frame) string {
+	if err, ok := v.(error); ok {
+		v = rootCause(err)
+	}
+
+	s := fmt.Sprintf("%T", v)
+	if frame.Function != "" {
+		s += fmt.Sprintf(" at %s (%s:%d)", path.Base(frame.Function), path.Base(frame.File), frame.Line)
+	}
+
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+
+	if len(s) <= maxExceptionDescription {
+		return s
+	}
+
+	s = s[:maxExceptionDescription]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// errorFrame returns the top stack frame recorded by github.com/pkg/errors in err or its causes.
+func errorFrame(err error) (runtime.Frame, bool) {
+	var (
+		frame runtime.Frame
+		found bool
+	)
+
+	for err != nil {
+		if st, ok := err.(stackTracer); ok && len(st.StackTrace()) > 0 {
+			// pkg/errors Frames are return addresses, the call is in the instruction before.
+			pc := uintptr(st.StackTrace()[0]) - 1
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				frame.Function = fn.Name()
+				frame.File, frame.Line = fn.FileLine(pc)
+				found = true
+			}
+		}
+
+		err = unwrapOnce(err)
+	}
+
+	return frame, found
+}
+
+// rootCause returns the error wrapped by err that carries a type of its own.
+// It follows github.com/pkg/errors causes and errors wrapped with fmt.Errorf,
+// but stops at errors like *fs.PathError that wrap another error themselves.
+func rootCause(err error) error {
+	for {
+		var next error
+		switch x := err.(type) {
+		case interface{ Cause() error }:
+			next = x.Cause()
+		case interface{ Unwrap() error }:
+			if strings.HasPrefix(fmt.Sprintf("%T", err), "*fmt.") {
+				next = x.Unwrap()
+			}
+		}
+
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+func unwrapOnce(err error) error {
+	switch x := err.(type) {
+	case interface{ Cause() error }:
+		return x.Cause()
+	case interface{ Unwrap() error }:
+		return x.Unwrap()
+	}
+	return nil
+}
+
+func callerFrame(skip int) runtime.Frame {
+	pc := make([]uintptr, 1)
+	if runtime.Callers(skip+1, pc) == 0 {
+		return runtime.Frame{}
+	}
+
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return frame
+}
+
+// panicFrame returns the frame that panicked, when called from a deferred function during a panic.
+func panicFrame() runtime.Frame {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	var panicking bool
+	for {
+		frame, more := frames.Next()
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame
+		}
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return runtime.Frame{}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package ga
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Client_ReportException(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+
+	err := c.ReportException(errors.New("secret user data"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.ReportException(http.ErrNoCookie, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.ReportException(nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.pending) != 2 {
+		t.Fatal(len(c.pending))
+	}
+
+	e := c.pending[0].e
+	if e.Get("t") != "exception" || e.Get("tid") != "UA-12345-1" || e.Get("cid") == "" || e.Get("exf") != "0" {
+		t.Fatal(e)
+	}
+	if !strings.HasPrefix(e.Get("exd"), "*errors.fundamental at ga.Test_Client_ReportException (exception_test.go:") || strings.Contains(e.Get("exd"), "secret") {
+		t.Fatal(e.Get("exd"))
+	}
+
+	e = c.pending[1].e
+	if e.Get("exf") != "1" || !strings.HasPrefix(e.Get("exd"), "*errors.errorString at ga.Test_Client_ReportException (exception_test.go:") {
+		t.Fatal(e)
+	}
+
+}
+
+func Test_Client_Recover(t *testing.T) {
+
+	reqChan := make(chan string, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		TID:       "UA-12345-1",
+		BatchWait: time.Hour,
+	}
+
+	c.urlStr = ts.URL
+
+	go c.Start()
+	defer c.Shutdown(context.Background())
+
+	time.Sleep(time.Millisecond * 10)
+
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Fatal(v)
+			}
+
+			// the hit left before panicking again
+			select {
+			case req := <-reqChan:
+				e, err := ParseEvent(strings.TrimSpace(req))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if e.Get("t") != "exception" || e.Get("exf") != "1" || !strings.HasPrefix(e.Get("exd"), "string at ga.Test_Client_Recover.func") {
+					t.Fatal(e)
+				}
+			default:
+				t.Fatal("expected req")
+			}
+		}()
+		defer c.Recover()
+
+		panic("boom")
+	}()
+
+}
+
+func Test_Client_RecoverHTTPHandler(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+
+	h := (&Middleware{Client: c}).Handler(c.RecoverHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]string
+		m["a"] = "b"
+	})))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatal(w.Code)
+	}
+
+	if len(c.pending) != 2 {
+		t.Fatal(len(c.pending))
+	}
+
+	pageview, exception := c.pending[0].e, c.pending[1].e
+	if exception.Get("t") != "exception" || exception.Get("cid") != pageview.Get("cid") || exception.Get("uip") != pageview.Get("uip") {
+		t.Fatal(exception)
+	}
+	if !strings.HasPrefix(exception.Get("exd"), "runtime.plainError at ga.Test_Client_RecoverHTTPHandler.func1 (exception_test.go:") {
+		t.Fatal(exception.Get("exd"))
+	}
+
+	abort := c.RecoverHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	func() {
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Fatal(v)
+			}
+		}()
+
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	if len(c.pending) != 2 {
+		t.Fatal(len(c.pending))
+	}
+
+}
+
+func Test_Client_ReportException_Wrapped(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+
+	pathErr := &os.PathError{Op: "open", Path: "/home/alice/secret", Err: os.ErrNotExist}
+
+	err := c.ReportException(errors.Wrap(pathErr, "loading config"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.ReportException(fmt.Errorf("loading config: %w", pathErr), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.pending) != 2 {
+		t.Fatal(len(c.pending))
+	}
+
+	// the stack of errors.Wrap is used, the type is the one of the wrapped error, not of its wrapped sentinel
+	exd := c.pending[0].e.Get("exd")
+	if !strings.HasPrefix(exd, "*fs.PathError at ga.Test_Client_ReportException_Wrapped (exception_test.go:") || strings.Contains(exd, "alice") {
+		t.Fatal(exd)
+	}
+
+	exd = c.pending[1].e.Get("exd")
+	if !strings.HasPrefix(exd, "*fs.PathError at ga.Test_Client_ReportException_Wrapped (exception_test.go:") {
+		t.Fatal(exd)
+	}
+
+}
+
+func Test_ExceptionDescription_Limit(t *testing.T) {
+
+	s := exceptionDescription(strings.Repeat("é", 200), callerFrame(1))
+	if len(s) > maxExceptionDescription || !strings.HasPrefix(s, "string at ") {
+		t.Fatal(s)
+	}
+
+}
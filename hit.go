@@ -0,0 +1,52 @@
+package ga
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Hit is an Event together with the time it was reported.
+// Hits are used to archive Events and replay them later with the correct queue time.
+type Hit struct {
+	ReportedAt time.Time `json:"reported_at"`
+	Event      Event     `json:"event"`
+}
+
+// A HitEncoder writes Hits to a stream as JSON Lines.
+type HitEncoder struct {
+	enc *json.Encoder
+}
+
+// NewHitEncoder returns a HitEncoder that writes to w.
+func NewHitEncoder(w io.Writer) *HitEncoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &HitEncoder{enc: enc}
+}
+
+// Encode writes h to the stream followed by a newline.
+func (e *HitEncoder) Encode(h Hit) error {
+	return e.enc.Encode(h)
+}
+
+// A HitDecoder reads Hits from a stream of JSON Lines.
+type HitDecoder struct {
+	dec *json.Decoder
+}
+
+// NewHitDecoder returns a HitDecoder that reads from r.
+func NewHitDecoder(r io.Reader) *HitDecoder {
+	return &HitDecoder{dec: json.NewDecoder(r)}
+}
+
+// Decode returns the next Hit in the stream.
+// At the end of the stream it returns io.EOF.
+func (d *HitDecoder) Decode() (Hit, error) {
+	var h Hit
+	err := d.dec.Decode(&h)
+	if err != nil {
+		return Hit{}, err
+	}
+	return h, nil
+}
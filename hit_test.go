@@ -0,0 +1,54 @@
+package ga
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func Test_Hit_Encode_Decode(t *testing.T) {
+	reportedAt := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	buf := bytes.NewBuffer(nil)
+	enc := NewHitEncoder(buf)
+
+	err := enc.Encode(Hit{ReportedAt: reportedAt, Event: Event{"t": "pageview", "dp": "/a&b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = enc.Encode(Hit{ReportedAt: reportedAt.Add(time.Second), Event: Event{"t": "event"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"reported_at":"2017-03-01T12:00:00Z","event":{"dp":"/a&b","t":"pageview"}}
+{"reported_at":"2017-03-01T12:00:01Z","event":{"t":"event"}}
+`
+	if buf.String() != expected {
+		t.Fatal(buf.String())
+	}
+
+	dec := NewHitDecoder(buf)
+
+	h, err := dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.ReportedAt.Equal(reportedAt) || h.Event.Get("dp") != "/a&b" {
+		t.Fatal(h)
+	}
+
+	h, err = dec.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Event.Get("t") != "event" {
+		t.Fatal(h)
+	}
+
+	_, err = dec.Decode()
+	if err != io.EOF {
+		t.Fatal(err)
+	}
+}
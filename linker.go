@@ -0,0 +1,141 @@
+package ga
+
+import (
+	"encoding/base64"
+	"hash/crc32"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// linkerParam is the query parameter analytics.js and gtag.js use to pass cookies across domains.
+const linkerParam = "_gl"
+
+// linkerMaxAge is the number of minutes a linker stays valid after it was created.
+const linkerMaxAge = 2
+
+// ParseLinker parses the value of a "_gl" linker parameter, as added to links by analytics.js and gtag.js,
+// and returns its decoded values by key, such as "_ga".
+//
+// The linker has the form "1*fingerprint*key*value*key*value" and is only valid for the browser
+// that created it and for a few minutes. The fingerprint is checked against the user agent ua and
+// language lang of the visitor at now. As the timezone of the visitor is unknown, every offset is tried.
+func ParseLinker(gl, ua, lang string, now time.Time) (map[string]string, error) {
+	parts := strings.Split(gl, "*")
+	if len(parts) < 4 || len(parts)%2 != 0 {
+		return nil, errors.Wrapf(ErrInvalidLinker, "%q", gl)
+	}
+	if parts[0] != "1" {
+		return nil, errors.Wrapf(ErrInvalidLinker, "version %q", parts[0])
+	}
+
+	fingerprint, kv := parts[1], strings.Join(parts[2:], "*")
+
+	if !validLinkerFingerprint(fingerprint, kv, ua, lang, now) {
+		return nil, errors.Wrap(ErrInvalidLinker, "fingerprint mismatch")
+	}
+
+	values := make(map[string]string, len(parts)/2-1)
+	for i := 2; i < len(parts); i += 2 {
+		v, err := decodeLinkerValue(parts[i+1])
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidLinker, "%s: %s", parts[i], err)
+		}
+		values[parts[i]] = v
+	}
+
+	return values, nil
+}
+
+// DecorateURL adds a "_gl" linker parameter to rawURL carrying the client id cid,
+// for the visitor making r. The destination adopts cid if it accepts linkers, see Middleware.Linker.
+//
+// The timezone of the visitor is unknown on the server, so the linker is created for UTC.
+// Middleware accepts linkers of every timezone, analytics.js on the destination only accepts it
+// for visitors whose browser uses UTC.
+func DecorateURL(rawURL, cid string, r *http.Request) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	kv := "_ga*" + base64.RawURLEncoding.EncodeToString([]byte(cid))
+	fingerprint := linkerFingerprint(kv, r.UserAgent(), acceptLanguage(r), 0, time.Now().Unix()/60)
+
+	// the other parameters are kept as they are, replacing an existing linker
+	query := filterQuery(u.RawQuery, func(key string) bool {
+		return key == linkerParam
+	})
+	if query != "" {
+		query += "&"
+	}
+	u.RawQuery = query + linkerParam + "=1*" + fingerprint + "*" + kv
+
+	return u.String(), nil
+}
+
+// linkerFingerprint returns the base36 CRC32 of "ua*tz*lang*minute*kv",
+// tz is the offset of the visitor as returned by Date.getTimezoneOffset in JavaScript.
+func linkerFingerprint(kv, ua, lang string, tz int, minute int64) string {
+	s := strings.Join([]string{ua, strconv.Itoa(tz), lang, strconv.FormatInt(minute, 10), kv}, "*")
+	return strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(s))), 36)
+}
+
+func validLinkerFingerprint(fingerprint, kv, ua, lang string, now time.Time) bool {
+	minute := now.Unix() / 60
+
+	for age := int64(0); age <= linkerMaxAge; age++ {
+		// timezone offsets range from UTC+14 to UTC-12, in steps of 15 minutes
+		for tz := -14 * 60; tz <= 12*60; tz += 15 {
+			if linkerFingerprint(kv, ua, lang, tz, minute-age) == fingerprint {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// decodeLinkerValue decodes web safe base64, with or without "." or "=" padding.
+func decodeLinkerValue(s string) (string, error) {
+	s = strings.TrimRight(s, ".=")
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		b, err = base64.RawStdEncoding.DecodeString(s)
+	}
+	return string(b), err
+}
+
+// acceptLanguage returns the preferred language of the visitor making r, as in navigator.language.
+func acceptLanguage(r *http.Request) string {
+	lang := r.Header.Get("Accept-Language")
+	if i := strings.IndexAny(lang, ",;"); i >= 0 {
+		lang = lang[:i]
+	}
+	return strings.TrimSpace(lang)
+}
+
+// linkerClientID returns the client id carried by a valid "_gl" parameter of r.
+func linkerClientID(r *http.Request, now time.Time) string {
+	gl := r.URL.Query().Get(linkerParam)
+	if gl == "" {
+		return ""
+	}
+
+	values, err := ParseLinker(gl, r.UserAgent(), acceptLanguage(r), now)
+	if err != nil {
+		return ""
+	}
+
+	cid := values["_ga"]
+	// the client id can be passed as the full cookie value, "GA1.2.1234567890.1234567890"
+	if parts := strings.SplitN(cid, ".", 3); len(parts) == 3 && strings.HasPrefix(parts[0], "GA") {
+		cid = parts[2]
+	}
+
+	return cid
+}
@@ -0,0 +1,111 @@
+package ga
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_ParseLinker(t *testing.T) {
+
+	ua := "Mozilla/5.0"
+	now := time.Now()
+
+	kv := "_ga*" + base64.RawURLEncoding.EncodeToString([]byte("1234567890.1500000000")) + "*_gcl_aw*" + base64.StdEncoding.EncodeToString([]byte("x"))
+	gl := "1*" + linkerFingerprint(kv, ua, "nl-BE", -120, now.Unix()/60-1) + "*" + kv
+
+	values, err := ParseLinker(gl, ua, "nl-BE", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["_ga"] != "1234567890.1500000000" || values["_gcl_aw"] != "x" {
+		t.Fatal(values)
+	}
+
+	tests := []struct {
+		gl, ua, lang string
+		now          time.Time
+	}{
+		{gl, "curl/7.0", "nl-BE", now},
+		{gl, ua, "en-US", now},
+		{gl, ua, "nl-BE", now.Add(time.Minute * 5)},
+		{"2" + gl[1:], ua, "nl-BE", now},
+		{"1*abc*_ga", ua, "nl-BE", now},
+		{"1*" + linkerFingerprint("_ga*%%%", ua, "nl-BE", 0, now.Unix()/60) + "*_ga*%%%", ua, "nl-BE", now},
+	}
+
+	for i, test := range tests {
+		_, err := ParseLinker(test.gl, test.ua, test.lang, test.now)
+		if errors.Cause(err) != ErrInvalidLinker {
+			t.Fatal(i, err)
+		}
+	}
+
+}
+
+func Test_Middleware_Linker(t *testing.T) {
+
+	c := &Client{}
+
+	m := &Middleware{
+		Client: c,
+		Cookie: "cid",
+		Linker: true,
+	}
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	out := httptest.NewRequest("GET", "https://marketing.test/", nil)
+	out.Header.Set("User-Agent", "Mozilla/5.0")
+	out.Header.Set("Accept-Language", "nl-BE,nl;q=0.9")
+
+	dst, err := DecorateURL("https://app.test/signup?plan=pro", "1234567890.1500000000", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(dst)
+	if u.Query().Get("plan") != "pro" || u.Query().Get("_gl") == "" {
+		t.Fatal(dst)
+	}
+
+	// existing parameters keep their order and encoding, an old linker is replaced
+	redecorated, err := DecorateURL("https://app.test/signup?z=1&_gl=old&a=b%20c&a=d#top", "1234567890.1500000000", out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(redecorated, "https://app.test/signup?z=1&a=b%20c&a=d&_gl=1*") || !strings.HasSuffix(redecorated, "#top") || strings.Contains(redecorated, "_gl=old") {
+		t.Fatal(redecorated)
+	}
+
+	r := httptest.NewRequest("GET", dst, nil)
+	r.Header = out.Header.Clone()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	// another visitor can't use the link to take over the client id
+	r = httptest.NewRequest("GET", dst, nil)
+	r.Header.Set("User-Agent", "curl/7.0")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	e := c.pending[0].e
+	if e.Get("cid") != "1234567890.1500000000" || e.Get("dp") != "/signup?plan=pro" {
+		t.Fatal(e)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != "1234567890.1500000000" {
+		t.Fatal(cookies)
+	}
+
+	if cid := c.pending[1].e.Get("cid"); cid == "" || cid == "1234567890.1500000000" {
+		t.Fatal(cid)
+	}
+
+}
@@ -0,0 +1,80 @@
+package ga
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxContentGroups is the number of content groups ("cg1".."cg5") GA supports.
+const maxContentGroups = 5
+
+// PathNormalizer returns the page path reported for a request, such as "/users/{id}" for "/users/8123".
+// It is called after the handler, so with Go 1.23 or later r.Pattern is set if the request was routed by http.ServeMux.
+type PathNormalizer interface {
+	Normalize(r *http.Request) string
+}
+
+// PathNormalizerFunc is an adapter to use ordinary functions as PathNormalizer.
+type PathNormalizerFunc func(r *http.Request) string
+
+// Normalize calls f(r).
+func (f PathNormalizerFunc) Normalize(r *http.Request) string {
+	return f(r)
+}
+
+// NormalizeRule replaces matches of Regexp in a page path with Replacement, as in regexp.Regexp.ReplaceAllString.
+type NormalizeRule struct {
+	Regexp      *regexp.Regexp
+	Replacement string
+}
+
+// RegexpNormalizer is a PathNormalizer that applies its rules in order to the path of the request.
+type RegexpNormalizer []NormalizeRule
+
+// Normalize returns the path of r with all rules applied.
+func (n RegexpNormalizer) Normalize(r *http.Request) string {
+	p := r.URL.Path
+	for _, rule := range n {
+		p = rule.Regexp.ReplaceAllString(p, rule.Replacement)
+	}
+	return p
+}
+
+// routePattern returns the path of the pattern that matched r, without method and host.
+// The pattern "GET example.com/users/{id}" has the path "/users/{id}".
+// The end anchor and the dots of wildcards matching the remainder of the path are removed,
+// "/{$}" is reported as "/" and "/static/{path...}" as "/static/{path}".
+func routePattern(r *http.Request) string {
+	p := requestPattern(r)
+	if i := strings.IndexAny(p, " \t"); i >= 0 {
+		p = strings.TrimLeft(p[i:], " \t")
+	}
+	i := strings.Index(p, "/")
+	if i < 0 {
+		return ""
+	}
+
+	p = strings.Replace(p[i:], "{$}", "", -1)
+	return strings.Replace(p, "...}", "}", -1)
+}
+
+// setContentGroups sets the content groups ("cg1".."cg5") of e to the leading segments of page path p.
+// The path "/users/{id}/orders" has the groups "/users", "/users/{id}" and "/users/{id}/orders".
+func setContentGroups(e Event, p string) {
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+
+	var group string
+	for i, s := range segments {
+		if i == maxContentGroups || s == "" {
+			return
+		}
+		group += "/" + s
+		e.Set("cg"+strconv.Itoa(i+1), group)
+	}
+}
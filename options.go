@@ -0,0 +1,205 @@
+package ga
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// An Option configures a Client created with New.
+type Option func(*Client) error
+
+// New returns a Client configured with opts.
+// Unlike the zero Client, the configuration is validated before the Client is used.
+func New(opts ...Option) (*Client, error) {
+	c := &Client{}
+
+	for _, opt := range opts {
+		err := opt(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+var tidRegexp = regexp.MustCompile(`^(UA|YT|MO)-\d+-\d+$`)
+
+// WithTID sets the GA ID used by Client.DefaultHTTPHandler.
+func WithTID(tid string) Option {
+	return func(c *Client) error {
+		if !tidRegexp.MatchString(tid) {
+			return errors.Wrapf(ErrInvalidOption, "tid %q", tid)
+		}
+
+		c.TID = tid
+		return nil
+	}
+}
+
+// WithHTTPClient sets the http.Client used to make POST calls to GA.
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) error {
+		if h == nil {
+			return errors.Wrap(ErrInvalidOption, "nil http client")
+		}
+
+		c.HTTP = h
+		return nil
+	}
+}
+
+// WithBatchWait sets how long the Client waits before reporting an Event to GA.
+func WithBatchWait(d time.Duration) Option {
+	return func(c *Client) error {
+		if d <= 0 {
+			return errors.Wrapf(ErrInvalidOption, "batch wait %s", d)
+		}
+
+		c.BatchWait = d
+		return nil
+	}
+}
+
+// WithMinBatchSize sets the number of buffered Events that makes the Client submit them without waiting for BatchWait.
+func WithMinBatchSize(n int) Option {
+	return func(c *Client) error {
+		if n <= 0 || n > 20 {
+			return errors.Wrapf(ErrInvalidOption, "min batch size %d", n)
+		}
+
+		c.MinBatchSize = n
+		return nil
+	}
+}
+
+// WithSendTimeout sets the time to wait for batch sends to complete.
+func WithSendTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d <= 0 {
+			return errors.Wrapf(ErrInvalidOption, "send timeout %s", d)
+		}
+
+		c.SendTimeout = d
+		return nil
+	}
+}
+
+// WithMaxQueueTime sets the age after which Events are dropped instead of submitted.
+// It can not exceed DefaultMaxQueueTime.
+func WithMaxQueueTime(d time.Duration) Option {
+	return func(c *Client) error {
+		if d <= 0 || d > DefaultMaxQueueTime {
+			return errors.Wrapf(ErrInvalidOption, "max queue time %s", d)
+		}
+
+		c.MaxQueueTime = d
+		return nil
+	}
+}
+
+// WithShutdownTimeout sets the longest time Run waits for the remaining Events to be submitted.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d <= 0 {
+			return errors.Wrapf(ErrInvalidOption, "shutdown timeout %s", d)
+		}
+
+		c.ShutdownTimeout = d
+		return nil
+	}
+}
+
+// WithCircuitBreaker enables the circuit breaker of the Client.
+// It opens after threshold consecutive failed submissions and probes the endpoint again after cooldown.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) error {
+		if threshold <= 0 {
+			return errors.Wrapf(ErrInvalidOption, "breaker threshold %d", threshold)
+		}
+		if cooldown <= 0 {
+			return errors.Wrapf(ErrInvalidOption, "breaker cooldown %s", cooldown)
+		}
+
+		c.BreakerThreshold = threshold
+		c.BreakerCooldown = cooldown
+		return nil
+	}
+}
+
+// WithMaxBufferedEvents sets the maximum number of Events the Client buffers.
+func WithMaxBufferedEvents(n int) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			return errors.Wrapf(ErrInvalidOption, "max buffered events %d", n)
+		}
+
+		c.MaxBufferedEvents = n
+		return nil
+	}
+}
+
+// WithCacheBust makes the Client add a cache buster to every submitted Event.
+func WithCacheBust() Option {
+	return func(c *Client) error {
+		c.CacheBust = true
+		return nil
+	}
+}
+
+// WithEndpoint sets the URL of the batch endpoint Events are submitted to.
+// This is useful to submit Events through a proxy.
+func WithEndpoint(endpoint string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidOption, "endpoint %q: %s", endpoint, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Wrapf(ErrInvalidOption, "endpoint %q", endpoint)
+		}
+
+		c.urlStr = endpoint
+		return nil
+	}
+}
+
+// WithErrHandler sets the ErrHandler to be used by the Client.
+func WithErrHandler(h ErrHandler) Option {
+	return func(c *Client) error {
+		if h == nil {
+			return errors.Wrap(ErrInvalidOption, "nil err handler")
+		}
+
+		c.HandleErr(h)
+		return nil
+	}
+}
+
+// WithProcessor adds a Processor to the Client.
+func WithProcessor(p Processor) Option {
+	return func(c *Client) error {
+		if p == nil {
+			return errors.Wrap(ErrInvalidOption, "nil processor")
+		}
+
+		c.Use(p)
+		return nil
+	}
+}
+
+// WithRegistry sets the Registry of custom dimensions and metrics.
+func WithRegistry(r *Registry) Option {
+	return func(c *Client) error {
+		if r == nil {
+			return errors.Wrap(ErrInvalidOption, "nil registry")
+		}
+
+		c.Registry = r
+		return nil
+	}
+}
@@ -0,0 +1,176 @@
+package ga
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_New(t *testing.T) {
+
+	c, err := New(
+		WithTID("UA-12345-1"),
+		WithHTTPClient(&http.Client{Timeout: time.Second}),
+		WithBatchWait(time.Second),
+		WithMinBatchSize(10),
+		WithSendTimeout(time.Second*2),
+		WithMaxQueueTime(time.Hour),
+		WithCacheBust(),
+		WithShutdownTimeout(time.Second*3),
+		WithMaxBufferedEvents(100),
+		WithCircuitBreaker(5, time.Minute),
+		WithEndpoint("https://example.com/batch"),
+		WithErrHandler(ErrHandlerFunc(func(e []Event, err error) {})),
+		WithProcessor(ProcessorFunc(func(e Event) Event { return e })),
+		WithRegistry(&Registry{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.TID != "UA-12345-1" || c.BatchWait != time.Second || c.MinBatchSize != 10 || c.SendTimeout != time.Second*2 || c.MaxQueueTime != time.Hour || !c.CacheBust || c.ShutdownTimeout != time.Second*3 || c.MaxBufferedEvents != 100 || c.BreakerThreshold != 5 || c.BreakerCooldown != time.Minute || c.urlStr != "https://example.com/batch" || c.errHandler == nil || len(c.processors) != 1 || c.Registry == nil {
+		t.Fatal(c)
+	}
+
+}
+
+func Test_New_Invalid(t *testing.T) {
+
+	opts := []Option{
+		WithTID("G-12345"),
+		WithHTTPClient(nil),
+		WithBatchWait(0),
+		WithMinBatchSize(21),
+		WithSendTimeout(-time.Second),
+		WithMaxQueueTime(time.Hour * 5),
+		WithShutdownTimeout(0),
+		WithMaxBufferedEvents(0),
+		WithCircuitBreaker(0, time.Minute),
+		WithCircuitBreaker(5, 0),
+		WithEndpoint("ftp://example.com"),
+		WithEndpoint("%zz"),
+		WithErrHandler(nil),
+		WithProcessor(nil),
+		WithRegistry(nil),
+	}
+
+	for i, opt := range opts {
+		_, err := New(opt)
+		if errors.Cause(err) != ErrInvalidOption {
+			t.Fatal(i, err)
+		}
+	}
+
+}
+
+func Test_Client_Run(t *testing.T) {
+
+	reqChan := make(chan string, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c, err := New(
+		WithEndpoint(ts.URL),
+		WithBatchWait(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- c.Run(ctx)
+	}()
+
+	err = c.Report(Event{"foo": "baz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return")
+	}
+
+	select {
+	case req := <-reqChan:
+		if req != "foo=baz" && req[:8] != "foo=baz&" {
+			t.Fatal(req)
+		}
+	default:
+		t.Fatal("expected req")
+	}
+
+	err = c.Report(Event{"foo": "baz"})
+	if err != ErrClientClosed {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Client_Run_Shutdown_Timeout(t *testing.T) {
+
+	unblock := make(chan struct{})
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-unblock
+		}),
+	)
+	defer ts.Close()
+	defer close(unblock)
+
+	c, err := New(
+		WithEndpoint(ts.URL),
+		WithBatchWait(time.Hour),
+		WithSendTimeout(time.Millisecond*50),
+		WithShutdownTimeout(time.Millisecond*200),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- c.Run(ctx)
+	}()
+
+	err = c.Report(Event{"foo": "baz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+
+	select {
+	case err := <-runErr:
+		if err != context.DeadlineExceeded {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected Run to give up")
+	}
+
+}
@@ -0,0 +1,110 @@
+package ga
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseEvent decodes a single urlencoded hit, as written by Event.WriteTo, into an Event.
+// If a key occurs more than once the last value wins.
+func ParseEvent(s string) (Event, error) {
+	e := Event{}
+
+	s = strings.TrimSuffix(s, "\r")
+	if s == "" {
+		return e, nil
+	}
+
+	for _, kv := range strings.Split(s, "&") {
+		if kv == "" {
+			continue
+		}
+
+		var k, v string
+		if i := strings.Index(kv, "="); i >= 0 {
+			k, v = kv[:i], kv[i+1:]
+		} else {
+			k = kv
+		}
+
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, errors.Wrapf(ErrMalformedEvent, "key %q: %s", k, err)
+		}
+		if key == "" {
+			return nil, errors.Wrapf(ErrMalformedEvent, "empty key in %q", kv)
+		}
+
+		value, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, errors.Wrapf(ErrMalformedEvent, "value for %q: %s", key, err)
+		}
+
+		e[key] = value
+	}
+
+	return e, nil
+}
+
+// ParseBatch decodes a newline separated batch of hits, as written for the batch endpoint, into Events.
+// Empty lines are skipped.
+func ParseBatch(s string) ([]Event, error) {
+	var l []Event
+
+	for i, line := range strings.Split(s, "\n") {
+		e, err := ParseEvent(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		if len(e) == 0 {
+			continue
+		}
+
+		l = append(l, e)
+	}
+
+	return l, nil
+}
+
+// A Decoder reads Events from a stream of newline separated hits.
+type Decoder struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode returns the next non-empty Event in the stream.
+// At the end of the stream it returns io.EOF.
+func (d *Decoder) Decode() (Event, error) {
+	for {
+		s, err := d.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if s == "" && err == io.EOF {
+			return nil, io.EOF
+		}
+
+		d.line++
+
+		e, perr := ParseEvent(strings.TrimSuffix(s, "\n"))
+		if perr != nil {
+			return nil, errors.Wrapf(perr, "line %d", d.line)
+		}
+		if len(e) > 0 {
+			return e, nil
+		}
+
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ga
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_ParseEvent(t *testing.T) {
+	e, err := ParseEvent("alpha=%40lpha&beta=%26eta&gamma=a+b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Get("alpha") != "@lpha" || e.Get("beta") != "&eta" || e.Get("gamma") != "a b" {
+		t.Fatal(e)
+	}
+
+	if len(e) != 3 {
+		t.Fatal(e)
+	}
+}
+
+func Test_ParseEvent_Malformed(t *testing.T) {
+	for _, s := range []string{"=foo", "alpha=%zz", "%zz=alpha"} {
+		_, err := ParseEvent(s)
+		if errors.Cause(err) != ErrMalformedEvent {
+			t.Fatal(s, err)
+		}
+	}
+}
+
+func Test_ParseEvent_RoundTrip(t *testing.T) {
+	e := Event{
+		"t":   "event",
+		"ec":  "café & bar",
+		"ea":  "100% = 1+1",
+		"el":  "line\nbreak",
+		"dp":  "/foo?bar=baz#qux",
+		"cd1": "☃",
+	}
+
+	buf := bytes.NewBuffer(nil)
+	_, err := e.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := ParseEvent(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x) != len(e) {
+		t.Fatal(x)
+	}
+	for k, v := range e {
+		if x.Get(k) != v {
+			t.Fatal(k, x.Get(k))
+		}
+	}
+}
+
+func Test_ParseBatch_RoundTrip(t *testing.T) {
+	a := Event{"alpha": "@lpha", "beta": "&eta"}
+	b := Event{"one": "*ne", "two": "!wo"}
+
+	l := events{
+		event{reportedAt: time.Now(), e: a},
+		event{reportedAt: time.Now(), e: Event{}},
+		event{reportedAt: time.Now(), e: b},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	_, err := l.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := ParseBatch(buf.String() + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x) != 2 || x[0].Get("alpha") != "@lpha" || x[1].Get("two") != "!wo" {
+		t.Fatal(x)
+	}
+}
+
+func Test_Decoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader("alpha=one\r\n\nbeta=two\ngamma=%zz"))
+
+	e, err := d.Decode()
+	if err != nil || e.Get("alpha") != "one" {
+		t.Fatal(e, err)
+	}
+
+	e, err = d.Decode()
+	if err != nil || e.Get("beta") != "two" {
+		t.Fatal(e, err)
+	}
+
+	_, err = d.Decode()
+	if errors.Cause(err) != ErrMalformedEvent {
+		t.Fatal(err)
+	}
+
+	_, err = d.Decode()
+	if err != io.EOF {
+		t.Fatal(err)
+	}
+}
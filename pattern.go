@@ -0,0 +1,11 @@
+//go:build go1.23
+// +build go1.23
+
+package ga
+
+import "net/http"
+
+// requestPattern returns the pattern http.ServeMux matched for r.
+func requestPattern(r *http.Request) string {
+	return r.Pattern
+}
@@ -0,0 +1,11 @@
+//go:build !go1.23
+// +build !go1.23
+
+package ga
+
+import "net/http"
+
+// requestPattern returns "", requests carry no pattern before Go 1.23.
+func requestPattern(r *http.Request) string {
+	return ""
+}
@@ -0,0 +1,65 @@
+//go:build go1.23
+// +build go1.23
+
+package ga
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func Test_Middleware_Normalize(t *testing.T) {
+
+	c := &Client{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}/orders/{order}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /static/{path...}", func(w http.ResponseWriter, r *http.Request) {})
+
+	m := &Middleware{
+		Client:           c,
+		RoutePatterns:    true,
+		RawPathDimension: 4,
+		ContentGroups:    true,
+	}
+
+	h := m.Handler(mux)
+
+	for _, uri := range []string{"/users/8123/orders/99?tab=items", "/missing", "/", "/static/css/site.css"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", uri, nil))
+	}
+
+	m.Normalizer = RegexpNormalizer{
+		{Regexp: regexp.MustCompile(`/\d+`), Replacement: "/{id}"},
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/8123/orders/99", nil))
+
+	e := c.pending[0].e
+	if e.Get("dp") != "/users/{id}/orders/{order}" || e.Get("cd4") != "/users/8123/orders/99?tab=items" || e.Get("cg1") != "/users" || e.Get("cg2") != "/users/{id}" || e.Get("cg4") != "/users/{id}/orders/{order}" || e.Get("cg5") != "" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[1].e
+	if e.Get("dp") != "/missing" || e.Get("cd4") != "" || e.Get("cg1") != "/missing" || e.Get("cg2") != "" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[2].e
+	if e.Get("dp") != "/" || e.Get("cd4") != "" || e.Get("cg1") != "" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[3].e
+	if e.Get("dp") != "/static/{path}" || e.Get("cd4") != "/static/css/site.css" || e.Get("cg1") != "/static" || e.Get("cg2") != "/static/{path}" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[4].e
+	if e.Get("dp") != "/users/{id}/orders/{id}" || e.Get("cd4") != "/users/8123/orders/99" {
+		t.Fatal(e)
+	}
+
+}
@@ -0,0 +1,165 @@
+package ga
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Size limits the Measurement Protocol imposes on hits.
+const (
+	maxHitSize   = 8192
+	maxBatchSize = 16384
+)
+
+// transparent 1x1 gif, as returned by the collection endpoint.
+var pixel = []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00\xff\xff\xff\x00\x00\x00!\xf9\x04\x01\x00\x00\x00\x00,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;")
+
+// Proxy is an http.Handler that accepts hits sent by browsers and reports them with Client.
+// It serves paths ending in "/collect" and "/batch", so it can be used as a first party
+// replacement for the Google Analytics collection endpoints.
+//
+// Hits are enriched with the IP address ("uip") and user agent ("ua") of the incoming request
+// and are subject to the Processors of the Client.
+type Proxy struct {
+	// Client reports the received hits.
+	Client *Client
+	// TrustForwarded makes Proxy take the IP address from the X-Forwarded-For header.
+	// Only enable this behind a load balancer or reverse proxy that sets the header.
+	TrustForwarded bool
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		hits []Event
+		err  error
+	)
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/collect"):
+		hits, err = p.collect(w, r)
+	case strings.HasSuffix(r.URL.Path, "/batch"):
+		hits, err = p.batch(w, r)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	uip := remoteIP(r, p.TrustForwarded)
+	ua := r.UserAgent()
+
+	for _, e := range hits {
+		if uip != "" {
+			e.Set("uip", uip)
+		}
+		if e.Get("ua") == "" && ua != "" {
+			e.Set("ua", ua)
+		}
+
+		err = p.Client.Report(e)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Write(pixel)
+}
+
+func (p *Proxy) collect(w http.ResponseWriter, r *http.Request) ([]Event, error) {
+	var s string
+
+	switch r.Method {
+	case http.MethodGet:
+		s = r.URL.RawQuery
+	case http.MethodPost:
+		b, err := readBody(w, r, maxHitSize)
+		if err != nil {
+			return nil, err
+		}
+		s = strings.TrimSuffix(string(b), "\n")
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return nil, ErrMalformedEvent
+	}
+
+	if len(s) > maxHitSize {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return nil, ErrMalformedEvent
+	}
+
+	e, err := ParseEvent(s)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, err
+	}
+	if len(e) == 0 {
+		return nil, nil
+	}
+
+	return []Event{e}, nil
+}
+
+func (p *Proxy) batch(w http.ResponseWriter, r *http.Request) ([]Event, error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return nil, ErrMalformedEvent
+	}
+
+	b, err := readBody(w, r, maxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, err := ParseBatch(string(b))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, err
+	}
+
+	if len(hits) > 20 {
+		http.Error(w, "too many hits in batch", http.StatusBadRequest)
+		return nil, ErrMalformedEvent
+	}
+
+	return hits, nil
+}
+
+func readBody(w http.ResponseWriter, r *http.Request, limit int64) ([]byte, error) {
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// remoteIP returns the IP address of the client that made r.
+func remoteIP(r *http.Request, trustForwarded bool) string {
+	if trustForwarded {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			if i := strings.Index(fwd, ","); i >= 0 {
+				fwd = fwd[:i]
+			}
+			return strings.TrimSpace(fwd)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,116 @@
+package ga
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Proxy(t *testing.T) {
+
+	reqChan := make(chan string, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Hour * 100,
+	}
+
+	c.urlStr = ts.URL
+
+	c.Use(ProcessorFunc(func(e Event) Event {
+		if e.Get("t") == "screenview" {
+			return nil
+		}
+		e.Set("ds", "proxy")
+		return e
+	}))
+
+	go func() {
+		err := c.Start()
+		if err != nil && err != ErrClientClosed {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+
+	p := &Proxy{Client: c, TrustForwarded: true}
+
+	r := httptest.NewRequest("GET", "/collect?v=1&t=pageview&dp=%2Fhome", nil)
+	r.Header.Set("User-Agent", "test-agent")
+	r.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, r)
+
+	if w.Code != 200 || w.Header().Get("Content-Type") != "image/gif" {
+		t.Fatal(w.Code, w.Body.String())
+	}
+
+	r = httptest.NewRequest("POST", "/ga/batch", strings.NewReader("v=1&t=event&ea=a\nv=1&t=screenview\n"))
+	r.RemoteAddr = "192.0.2.1:1234"
+	w = httptest.NewRecorder()
+	(&Proxy{Client: c}).ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Fatal(w.Code, w.Body.String())
+	}
+
+	err := c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case req := <-reqChan:
+		expected := "^dp=%2Fhome&ds=proxy&(qt=\\d*&)?t=pageview&ua=test-agent&uip=10.0.0.1&v=1\nds=proxy&ea=a&(qt=\\d*&)?t=event&uip=192.0.2.1&v=1$"
+		if match, _ := regexp.MatchString(expected, req); !match {
+			t.Fatal(req)
+		}
+	case <-time.After(time.Millisecond * 500):
+		t.Fatal("expected req")
+	}
+
+}
+
+func Test_Proxy_Bad_Requests(t *testing.T) {
+
+	p := &Proxy{Client: &Client{}}
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{"GET", "/foo", "", 404},
+		{"PUT", "/collect", "", 405},
+		{"GET", "/batch", "", 405},
+		{"GET", "/collect?=x", "", 400},
+		{"POST", "/collect", strings.Repeat("a", maxHitSize+1), 413},
+		{"POST", "/batch", strings.Repeat("t=event\n", 21), 400},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+
+		if w.Code != test.code {
+			t.Fatal(test.method, test.target, w.Code)
+		}
+	}
+
+}
@@ -0,0 +1,187 @@
+package ga
+
+import (
+	_ "embed" // for the list of known referrers
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ReferrerKind classifies the referrer of a pageview.
+type ReferrerKind int
+
+// Kinds of referrers.
+const (
+	// ReferrerNone is an empty or malformed referrer.
+	ReferrerNone ReferrerKind = iota
+	// ReferrerInternal is a page of the site itself.
+	ReferrerInternal
+	// ReferrerSearch is a known search engine.
+	ReferrerSearch
+	// ReferrerSocial is a known social network.
+	ReferrerSocial
+	// ReferrerOther is any other site.
+	ReferrerOther
+)
+
+func (k ReferrerKind) String() string {
+	switch k {
+	case ReferrerNone:
+		return "none"
+	case ReferrerInternal:
+		return "internal"
+	case ReferrerSearch:
+		return "search"
+	case ReferrerSocial:
+		return "social"
+	case ReferrerOther:
+		return "other"
+	}
+	return "unknown"
+}
+
+// Referrer is the classification of a referrer URL.
+type Referrer struct {
+	Kind ReferrerKind
+	// Source is the name of the search engine or social network, such as "Google",
+	// or the host of other referrers.
+	Source string
+}
+
+//go:embed referrers.json
+var referrersJSON []byte
+
+var (
+	knownReferrersOnce sync.Once
+	knownReferrers     map[string]Referrer // by domain, "google.*" matches "google.de" and "www.google.co.uk", but not other subdomains
+)
+
+func loadKnownReferrers() {
+	knownReferrersOnce.Do(func() {
+		var list struct {
+			Search map[string][]string `json:"search"`
+			Social map[string][]string `json:"social"`
+		}
+		err := json.Unmarshal(referrersJSON, &list)
+		if err != nil {
+			panic("ga: malformed referrers.json: " + err.Error())
+		}
+
+		knownReferrers = make(map[string]Referrer)
+		for source, domains := range list.Search {
+			for _, d := range domains {
+				knownReferrers[d] = Referrer{Kind: ReferrerSearch, Source: source}
+			}
+		}
+		for source, domains := range list.Social {
+			for _, d := range domains {
+				knownReferrers[d] = Referrer{Kind: ReferrerSocial, Source: source}
+			}
+		}
+	})
+}
+
+// ReferrerClassifier classifies referrers of pageviews, to drop self-referrals
+// and attribute traffic from search engines and social networks.
+// Search engines and social networks are recognized from a list embedded in the package.
+type ReferrerClassifier struct {
+	// InternalDomains are the domains of the site.
+	// Referrers from these domains, their subdomains and the host of the request are internal.
+	InternalDomains []string
+}
+
+// Classify returns the classification of referrer for a request to host.
+func (c *ReferrerClassifier) Classify(referrer, host string) Referrer {
+	if referrer == "" {
+		return Referrer{}
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return Referrer{}
+	}
+
+	refHost := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	if h := strings.ToLower(host); h != "" {
+		if i := strings.LastIndex(h, ":"); i >= 0 && !strings.Contains(h[i:], "]") {
+			h = h[:i]
+		}
+		if refHost == h {
+			return Referrer{Kind: ReferrerInternal, Source: refHost}
+		}
+	}
+	for _, d := range c.InternalDomains {
+		if matchDomain(refHost, strings.ToLower(d)) {
+			return Referrer{Kind: ReferrerInternal, Source: refHost}
+		}
+	}
+
+	loadKnownReferrers()
+
+	// try the host and its parent domains, "www.google.co.uk", "google.co.uk", "co.uk", "uk"
+	for h := refHost; h != ""; {
+		if ref, ok := knownReferrers[h]; ok {
+			return ref
+		}
+
+		// wildcards only match the domain itself or its www subdomain,
+		// "mail.google.com" is not a search engine
+		labels := strings.SplitN(h, ".", 2)
+		if len(labels) == 2 && isTopLevelDomain(labels[1]) && (h == refHost || "www."+h == refHost) {
+			if ref, ok := knownReferrers[labels[0]+".*"]; ok {
+				return ref
+			}
+		}
+
+		i := strings.Index(h, ".")
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+
+	return Referrer{Kind: ReferrerOther, Source: refHost}
+}
+
+// isTopLevelDomain reports whether s looks like a (country code) top level domain, such as "de" or "co.uk".
+func isTopLevelDomain(s string) bool {
+	labels := strings.Split(s, ".")
+	if len(labels) > 2 {
+		return false
+	}
+	for _, l := range labels {
+		if l == "" || len(l) > 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// matchDomain reports whether host is domain or one of its subdomains.
+func matchDomain(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// apply classifies the referrer ("dr") of the pageview e for a request to host.
+// Internal referrers are dropped, search engines and social networks set the campaign source ("cs")
+// and medium ("cm") unless e already carries a campaign.
+func (c *ReferrerClassifier) apply(e Event, host string) {
+	ref := c.Classify(e.Get("dr"), host)
+
+	switch ref.Kind {
+	case ReferrerInternal:
+		e.Del("dr")
+	case ReferrerSearch, ReferrerSocial:
+		if e.Get("cs") != "" || e.Get("gclid") != "" || e.Get("dclid") != "" {
+			return
+		}
+		e.Set("cs", strings.ToLower(ref.Source))
+		if ref.Kind == ReferrerSearch {
+			e.Set("cm", "organic")
+		} else {
+			e.Set("cm", "social")
+		}
+	}
+}
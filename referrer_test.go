@@ -0,0 +1,87 @@
+package ga
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_ReferrerClassifier(t *testing.T) {
+
+	c := &ReferrerClassifier{InternalDomains: []string{"example.com"}}
+
+	tests := []struct {
+		referrer string
+		host     string
+		ref      Referrer
+	}{
+		{"", "shop.test", Referrer{}},
+		{"::", "shop.test", Referrer{}},
+		{"https://shop.test:8080/cart", "shop.test:8080", Referrer{ReferrerInternal, "shop.test"}},
+		{"https://blog.example.com/post", "shop.test", Referrer{ReferrerInternal, "blog.example.com"}},
+		{"https://notexample.com/", "shop.test", Referrer{ReferrerOther, "notexample.com"}},
+		{"https://www.google.co.uk/", "shop.test", Referrer{ReferrerSearch, "Google"}},
+		{"https://www.google.com/search?q=shoes", "shop.test", Referrer{ReferrerSearch, "Google"}},
+		{"https://duckduckgo.com/", "shop.test", Referrer{ReferrerSearch, "DuckDuckGo"}},
+		{"https://l.facebook.com/l.php", "shop.test", Referrer{ReferrerSocial, "Facebook"}},
+		{"https://t.co/abc", "shop.test", Referrer{ReferrerSocial, "Twitter"}},
+		{"https://google.example.org/", "shop.test", Referrer{ReferrerOther, "google.example.org"}},
+		{"https://google.de/", "shop.test", Referrer{ReferrerSearch, "Google"}},
+		{"https://mail.google.com/mail/u/0/", "shop.test", Referrer{ReferrerOther, "mail.google.com"}},
+		{"https://docs.google.com/document/d/1", "shop.test", Referrer{ReferrerOther, "docs.google.com"}},
+		{"https://mail.yahoo.com/", "shop.test", Referrer{ReferrerOther, "mail.yahoo.com"}},
+		{"https://uk.search.yahoo.com/search", "shop.test", Referrer{ReferrerSearch, "Yahoo"}},
+	}
+
+	for i, test := range tests {
+		ref := c.Classify(test.referrer, test.host)
+		if ref != test.ref {
+			t.Fatal(i, ref)
+		}
+	}
+
+}
+
+func Test_Middleware_Referrers(t *testing.T) {
+
+	c := &Client{}
+
+	m := &Middleware{
+		Client:    c,
+		Referrers: &ReferrerClassifier{InternalDomains: []string{"example.com"}},
+	}
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, test := range []struct{ uri, referrer string }{
+		{"/", "https://www.example.com/"},
+		{"/", "https://www.bing.com/"},
+		{"/", "https://www.reddit.com/r/golang"},
+		{"/?utm_source=newsletter", "https://www.bing.com/"},
+	} {
+		r := httptest.NewRequest("GET", test.uri, nil)
+		r.Header.Set("Referer", test.referrer)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	e := c.pending[0].e
+	if e.Get("dr") != "" || e.Get("cs") != "" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[1].e
+	if e.Get("dr") != "https://www.bing.com/" || e.Get("cs") != "bing" || e.Get("cm") != "organic" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[2].e
+	if e.Get("cs") != "reddit" || e.Get("cm") != "social" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[3].e
+	if e.Get("cs") != "newsletter" || e.Get("cm") != "" {
+		t.Fatal(e)
+	}
+
+}
@@ -0,0 +1,215 @@
+package ga
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Scope is the scope of a custom dimension or metric, as configured in the GA property.
+type Scope int
+
+// Scopes of custom dimensions and metrics.
+// Metrics are either hit or product scoped.
+const (
+	ScopeHit Scope = iota
+	ScopeSession
+	ScopeUser
+	ScopeProduct
+)
+
+var scopeNames = [...]string{
+	ScopeHit:     "hit",
+	ScopeSession: "session",
+	ScopeUser:    "user",
+	ScopeProduct: "product",
+}
+
+func (s Scope) String() string {
+	if s < 0 || int(s) >= len(scopeNames) {
+		return "Scope(" + strconv.Itoa(int(s)) + ")"
+	}
+	return scopeNames[s]
+}
+
+// MarshalText encodes s as its name.
+func (s Scope) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(scopeNames) {
+		return nil, errors.Wrapf(ErrInvalidDefinition, "scope %d", int(s))
+	}
+	return []byte(scopeNames[s]), nil
+}
+
+// UnmarshalText decodes a scope name.
+func (s *Scope) UnmarshalText(b []byte) error {
+	for i, name := range scopeNames {
+		if string(b) == name {
+			*s = Scope(i)
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrInvalidDefinition, "scope %q", b)
+}
+
+// Definition names a custom dimension or metric.
+type Definition struct {
+	Name  string `json:"name"`
+	Index int    `json:"index"`
+	Scope Scope  `json:"scope"`
+	// Metric is set for custom metrics, Definitions are custom dimensions otherwise.
+	Metric bool `json:"-"`
+}
+
+// Param returns the parameter of the definition, such as "cd3" or "cm1".
+// Product scoped definitions are set on products, where the parameter is prefixed
+// with the product, as in "pr1cd3". See Product.Dimensions and Product.Metrics.
+func (d Definition) Param() string {
+	if d.Metric {
+		return "cm" + strconv.Itoa(d.Index)
+	}
+	return "cd" + strconv.Itoa(d.Index)
+}
+
+// Registry maps names to the custom dimensions and metrics of a GA property,
+// so call sites don't depend on their indexes.
+// A Registry is immutable and safe for use by multiple go routines.
+type Registry struct {
+	names      map[string]Definition
+	dimensions map[int]Definition
+	metrics    map[int]Definition
+}
+
+// NewRegistry returns a Registry holding defs.
+// Names must be unique and indexes must be in the range GA accepts and unique per kind.
+func NewRegistry(defs ...Definition) (*Registry, error) {
+	r := &Registry{
+		names:      make(map[string]Definition, len(defs)),
+		dimensions: make(map[int]Definition),
+		metrics:    make(map[int]Definition),
+	}
+
+	for _, d := range defs {
+		if d.Name == "" {
+			return nil, errors.Wrapf(ErrInvalidDefinition, "%s without name", d.Param())
+		}
+		if d.Index < 1 || d.Index > maxIndex {
+			return nil, errors.Wrapf(ErrInvalidDefinition, "%q index %d", d.Name, d.Index)
+		}
+		if d.Scope < ScopeHit || d.Scope > ScopeProduct || (d.Metric && d.Scope != ScopeHit && d.Scope != ScopeProduct) {
+			return nil, errors.Wrapf(ErrInvalidDefinition, "%q scope %s", d.Name, d.Scope)
+		}
+		if _, ok := r.names[d.Name]; ok {
+			return nil, errors.Wrapf(ErrInvalidDefinition, "duplicate name %q", d.Name)
+		}
+
+		indexes := r.dimensions
+		if d.Metric {
+			indexes = r.metrics
+		}
+		if other, ok := indexes[d.Index]; ok {
+			return nil, errors.Wrapf(ErrInvalidDefinition, "%q and %q share %s", other.Name, d.Name, d.Param())
+		}
+
+		r.names[d.Name] = d
+		indexes[d.Index] = d
+	}
+
+	return r, nil
+}
+
+// LoadRegistry reads a Registry from JSON of the form:
+//
+//	{
+//		"dimensions": [{"name": "plan", "index": 1, "scope": "user"}],
+//		"metrics": [{"name": "score", "index": 1, "scope": "hit"}]
+//	}
+func LoadRegistry(r io.Reader) (*Registry, error) {
+	var config struct {
+		Dimensions []Definition `json:"dimensions"`
+		Metrics    []Definition `json:"metrics"`
+	}
+
+	err := json.NewDecoder(r).Decode(&config)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidDefinition, err.Error())
+	}
+
+	defs := config.Dimensions
+	for _, d := range config.Metrics {
+		d.Metric = true
+		defs = append(defs, d)
+	}
+
+	return NewRegistry(defs...)
+}
+
+// Lookup returns the Definition of name.
+func (r *Registry) Lookup(name string) (Definition, bool) {
+	if r == nil {
+		return Definition{}, false
+	}
+	d, ok := r.names[name]
+	return d, ok
+}
+
+// SetNamed sets the custom dimension or metric registered as name in r to value.
+// Product scoped definitions must be set on products, see Registry.Lookup and Product.Dimensions.
+func (e Event) SetNamed(r *Registry, name, value string) error {
+	d, ok := r.Lookup(name)
+	if !ok {
+		return errors.Wrapf(ErrUnknownDefinition, "%q", name)
+	}
+	if d.Scope == ScopeProduct {
+		return errors.Wrapf(ErrScopeMismatch, "%q is product scoped", name)
+	}
+
+	e.Set(d.Param(), value)
+	return nil
+}
+
+var customParamRegexp = regexp.MustCompile(`^(pr\d+|il\d+pi\d+)?(cd|cm)(\d+)$`)
+
+// Validate checks the custom dimensions and metrics of e against r.
+// It returns ErrUnknownDefinition for indexes that are not registered
+// and ErrScopeMismatch for product scoped definitions set on the hit or the other way around.
+func (r *Registry) Validate(e Event) error {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		m := customParamRegexp.FindStringSubmatch(k)
+		if m == nil {
+			continue
+		}
+
+		index, _ := strconv.Atoi(m[3])
+
+		var (
+			d  Definition
+			ok bool
+		)
+		if r != nil {
+			if m[2] == "cm" {
+				d, ok = r.metrics[index]
+			} else {
+				d, ok = r.dimensions[index]
+			}
+		}
+		if !ok {
+			return errors.Wrapf(ErrUnknownDefinition, "%s", k)
+		}
+
+		if product := m[1] != ""; product != (d.Scope == ScopeProduct) {
+			return errors.Wrapf(ErrScopeMismatch, "%s is %q with scope %s", k, d.Name, d.Scope)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package ga
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Registry(t *testing.T) {
+
+	r, err := LoadRegistry(strings.NewReader(`{
+		"dimensions": [
+			{"name": "plan", "index": 1, "scope": "user"},
+			{"name": "tenant", "index": 2, "scope": "session"},
+			{"name": "color", "index": 3, "scope": "product"}
+		],
+		"metrics": [
+			{"name": "score", "index": 1, "scope": "hit"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := Event{"t": "pageview"}
+
+	err = e.SetNamed(r, "plan", "pro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.SetNamed(r, "score", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Get("cd1") != "pro" || e.Get("cm1") != "5" {
+		t.Fatal(e)
+	}
+
+	err = e.SetNamed(r, "experiment", "a")
+	if errors.Cause(err) != ErrUnknownDefinition {
+		t.Fatal(err)
+	}
+	err = e.SetNamed(r, "color", "red")
+	if errors.Cause(err) != ErrScopeMismatch {
+		t.Fatal(err)
+	}
+
+	d, ok := r.Lookup("color")
+	if !ok || d.Index != 3 || d.Scope != ScopeProduct || d.Param() != "cd3" {
+		t.Fatal(d)
+	}
+
+	tests := []struct {
+		e   Event
+		err error
+	}{
+		{e, nil},
+		{Event{"pr1cd3": "red", "il1pi2cd3": "blue", "cd2": "acme"}, nil},
+		{Event{"cd4": "x"}, ErrUnknownDefinition},
+		{Event{"cm2": "1"}, ErrUnknownDefinition},
+		{Event{"cd3": "red"}, ErrScopeMismatch},
+		{Event{"pr1cd1": "pro"}, ErrScopeMismatch},
+	}
+
+	for i, test := range tests {
+		err := r.Validate(test.e)
+		if errors.Cause(err) != test.err {
+			t.Fatal(i, err)
+		}
+	}
+
+}
+
+func Test_Registry_Invalid(t *testing.T) {
+
+	tests := [][]Definition{
+		{{Index: 1}},
+		{{Name: "a", Index: 0}},
+		{{Name: "a", Index: 201}},
+		{{Name: "a", Index: 1, Scope: ScopeUser, Metric: true}},
+		{{Name: "a", Index: 1}, {Name: "a", Index: 2}},
+		{{Name: "a", Index: 1}, {Name: "b", Index: 1}},
+	}
+
+	for i, defs := range tests {
+		_, err := NewRegistry(defs...)
+		if errors.Cause(err) != ErrInvalidDefinition {
+			t.Fatal(i, err)
+		}
+	}
+
+	_, err := NewRegistry(Definition{Name: "a", Index: 1}, Definition{Name: "b", Index: 1, Metric: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadRegistry(strings.NewReader(`{"dimensions": [{"name": "a", "index": 1, "scope": "visitor"}]}`))
+	if errors.Cause(err) != ErrInvalidDefinition {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Client_Registry(t *testing.T) {
+
+	r, err := NewRegistry(Definition{Name: "plan", Index: 1, Scope: ScopeUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{Registry: r}
+
+	var flagged []error
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		flagged = append(flagged, err)
+	}))
+
+	err = c.Report(Event{"t": "pageview", "cd1": "pro"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Report(Event{"t": "pageview", "cd7": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(flagged) != 1 || errors.Cause(flagged[0]) != ErrUnknownDefinition {
+		t.Fatal(flagged)
+	}
+
+	if len(c.pending) != 2 {
+		t.Fatal(len(c.pending))
+	}
+
+}
@@ -0,0 +1,107 @@
+package ga
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxQueueTime is the longest queue time ("qt") the Measurement Protocol accepts.
+// Google Analytics silently discards hits with a higher queue time.
+const DefaultMaxQueueTime = time.Hour * 4
+
+// Replayer reports archived Hits through a Client.
+// Hits keep their original ReportedAt time, so the Client submits them with an accurate queue time.
+type Replayer struct {
+	// Client reports the replayed Hits.
+	Client *Client
+	// Hits reported longer ago than MaxQueueTime are dropped and passed to the ErrHandler of the Client.
+	// The default is DefaultMaxQueueTime.
+	MaxQueueTime time.Duration
+	// Rate is the maximum number of Hits reported per second.
+	// Zero means no limit.
+	Rate int
+}
+
+// ReplayStats describes the outcome of Replayer.Replay.
+type ReplayStats struct {
+	// Reported is the number of Hits reported to the Client.
+	Reported int
+	// Dropped is the number of Hits that exceeded the maximum queue time.
+	Dropped int
+}
+
+// Replay reads Hits as JSON Lines from r and reports them to the Client.
+// Hits without a ReportedAt time are reported as if they occurred now.
+// It returns when r is exhausted, ctx is done or the Client refuses an Event.
+// While the Client buffers MaxBufferedEvents, Replay waits for it to submit some.
+func (p *Replayer) Replay(ctx context.Context, r io.Reader) (ReplayStats, error) {
+	var stats ReplayStats
+
+	maxQueueTime := p.MaxQueueTime
+	if maxQueueTime == 0 {
+		maxQueueTime = DefaultMaxQueueTime
+	}
+
+	var throttle <-chan time.Time
+	if p.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(p.Rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	dec := NewHitDecoder(r)
+
+	for {
+		h, err := dec.Decode()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		if len(h.Event) == 0 {
+			continue
+		}
+
+		if h.ReportedAt.IsZero() {
+			h.ReportedAt = time.Now()
+		}
+
+		if age := time.Since(h.ReportedAt); age > maxQueueTime {
+			stats.Dropped++
+			p.Client.handleErr([]Event{h.Event}, errors.Wrapf(ErrQueueTimeExceeded, "reported %s ago", age))
+			continue
+		}
+
+		if throttle != nil {
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-throttle:
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			default:
+			}
+		}
+
+		// wait for room instead of dropping Hits while the Client can't keep up
+		err = p.Client.waitBuffer(ctx)
+		if err != nil {
+			return stats, err
+		}
+
+		err = p.Client.ReportAt(h.Event, h.ReportedAt)
+		if err != nil {
+			return stats, err
+		}
+
+		stats.Reported++
+	}
+}
@@ -0,0 +1,138 @@
+package ga
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Replayer_Replay(t *testing.T) {
+
+	reqChan := make(chan string, 1)
+	errChan := make(chan error, 1)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Hour * 100,
+	}
+
+	c.urlStr = ts.URL
+
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		if len(e) != 1 || e[0].Get("foo") != "stale" {
+			t.Error(e)
+		}
+		errChan <- err
+	}))
+
+	go func() {
+		err := c.Start()
+		if err != nil && err != ErrClientClosed {
+			t.Error(err)
+		}
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+
+	buf := bytes.NewBuffer(nil)
+	enc := NewHitEncoder(buf)
+	enc.Encode(Hit{ReportedAt: time.Now().Add(-time.Minute), Event: Event{"foo": "fresh"}})
+	enc.Encode(Hit{ReportedAt: time.Now().Add(-time.Hour * 5), Event: Event{"foo": "stale"}})
+	enc.Encode(Hit{Event: Event{"foo": "now"}})
+
+	p := &Replayer{Client: c, Rate: 20}
+
+	start := time.Now()
+
+	stats, err := p.Replay(context.Background(), buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Reported != 2 || stats.Dropped != 1 {
+		t.Fatal(stats)
+	}
+
+	if time.Since(start) < time.Millisecond*100 {
+		t.Fatal("expected throttling")
+	}
+
+	select {
+	case err := <-errChan:
+		if errors.Cause(err) != ErrQueueTimeExceeded {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("expected err")
+	}
+
+	err = c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case req := <-reqChan:
+		if match, _ := regexp.MatchString("^foo=fresh&qt=60\\d\\d\\d\\nfoo=now(&qt=\\d*)?$", req); !match {
+			t.Fatal(req)
+		}
+	case <-time.After(time.Millisecond * 500):
+		t.Fatal("expected req")
+	}
+
+}
+
+func Test_Replayer_Replay_Canceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	buf := bytes.NewBuffer(nil)
+	NewHitEncoder(buf).Encode(Hit{Event: Event{"foo": "baz"}})
+
+	p := &Replayer{Client: &Client{}}
+
+	_, err := p.Replay(ctx, buf)
+	if err != context.Canceled {
+		t.Fatal(err)
+	}
+
+}
+
+func Test_Replayer_Replay_BufferFull(t *testing.T) {
+
+	buf := bytes.NewBuffer(nil)
+	enc := NewHitEncoder(buf)
+	for i := 0; i < 3; i++ {
+		enc.Encode(Hit{Event: Event{"foo": "baz"}})
+	}
+
+	c := &Client{MaxBufferedEvents: 2}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	p := &Replayer{Client: c}
+
+	// the Client is not started, so the third Hit waits until ctx is done
+	stats, err := p.Replay(ctx, buf)
+	if err != context.DeadlineExceeded || stats.Reported != 2 {
+		t.Fatal(stats, err)
+	}
+
+}
@@ -0,0 +1,144 @@
+package ga
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultSessionTimeout is the inactivity after which Google Analytics starts a new session.
+const DefaultSessionTimeout = time.Minute * 30
+
+// SessionStore keeps the time of the last hit of each client id.
+// It must be safe for use by multiple go routines.
+type SessionStore interface {
+	// Get returns the time of the last hit of cid.
+	Get(cid string) (time.Time, bool)
+	// Set stores the time of the last hit of cid.
+	Set(cid string, lastHit time.Time)
+	// Delete removes cid.
+	Delete(cid string)
+}
+
+// SessionTracker adds session control ("sc") to Events, keyed by client id ("cid").
+// The first Event of a client id after Timeout of inactivity starts a new session.
+// SessionTracker is a Processor, so it can also be used with Client.Use.
+type SessionTracker struct {
+	// The inactivity after which the next Event starts a new session.
+	// The default is DefaultSessionTimeout.
+	Timeout time.Duration
+	// Store keeps the session state.
+	// The default is an in-memory LRU store holding 10000 client ids.
+	Store SessionStore
+
+	once sync.Once
+}
+
+func (s *SessionTracker) init() {
+	s.once.Do(func() {
+		if s.Timeout == 0 {
+			s.Timeout = DefaultSessionTimeout
+		}
+		if s.Store == nil {
+			s.Store = NewLRUSessionStore(10000)
+		}
+	})
+}
+
+// Process sets "sc=start" on e if it starts a new session and records the hit.
+// Events without a client id are returned as is.
+func (s *SessionTracker) Process(e Event) Event {
+	s.init()
+
+	cid := e.Get("cid")
+	if cid == "" {
+		return e
+	}
+
+	now := time.Now()
+
+	if e.Get("sc") == "end" {
+		s.Store.Delete(cid)
+		return e
+	}
+
+	last, ok := s.Store.Get(cid)
+	if (!ok || now.Sub(last) >= s.Timeout) && e.Get("sc") == "" {
+		e.Set("sc", "start")
+	}
+
+	s.Store.Set(cid, now)
+
+	return e
+}
+
+// End sets "sc=end" on e and forgets the session of its client id.
+func (s *SessionTracker) End(e Event) Event {
+	e.Set("sc", "end")
+	return s.Process(e)
+}
+
+// lruSessionStore is a SessionStore that holds a limited number of client ids,
+// evicting the least recently used.
+type lruSessionStore struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruSessionEntry struct {
+	cid     string
+	lastHit time.Time
+}
+
+// NewLRUSessionStore returns an in-memory SessionStore holding at most size client ids.
+func NewLRUSessionStore(size int) SessionStore {
+	return &lruSessionStore{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (s *lruSessionStore) Get(cid string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[cid]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	s.ll.MoveToFront(el)
+	return el.Value.(*lruSessionEntry).lastHit, true
+}
+
+func (s *lruSessionStore) Set(cid string, lastHit time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[cid]; ok {
+		el.Value.(*lruSessionEntry).lastHit = lastHit
+		s.ll.MoveToFront(el)
+		return
+	}
+
+	s.items[cid] = s.ll.PushFront(&lruSessionEntry{cid: cid, lastHit: lastHit})
+
+	for s.size > 0 && s.ll.Len() > s.size {
+		el := s.ll.Back()
+		s.ll.Remove(el)
+		delete(s.items, el.Value.(*lruSessionEntry).cid)
+	}
+}
+
+func (s *lruSessionStore) Delete(cid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[cid]; ok {
+		s.ll.Remove(el)
+		delete(s.items, cid)
+	}
+}
@@ -0,0 +1,81 @@
+package ga
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_SessionTracker(t *testing.T) {
+
+	s := &SessionTracker{Timeout: time.Millisecond * 50}
+
+	e := s.Process(Event{"cid": "a"})
+	if e.Get("sc") != "start" {
+		t.Fatal(e)
+	}
+
+	e = s.Process(Event{"cid": "a"})
+	if e.Get("sc") != "" {
+		t.Fatal(e)
+	}
+
+	e = s.Process(Event{"cid": "b"})
+	if e.Get("sc") != "start" {
+		t.Fatal(e)
+	}
+
+	time.Sleep(time.Millisecond * 60)
+
+	e = s.Process(Event{"cid": "a"})
+	if e.Get("sc") != "start" {
+		t.Fatal(e)
+	}
+
+	e = s.End(Event{"cid": "a"})
+	if e.Get("sc") != "end" {
+		t.Fatal(e)
+	}
+
+	e = s.Process(Event{"cid": "a"})
+	if e.Get("sc") != "start" {
+		t.Fatal(e)
+	}
+
+	e = s.Process(Event{"foo": "baz"})
+	if e.Get("sc") != "" {
+		t.Fatal(e)
+	}
+
+}
+
+func Test_LRUSessionStore(t *testing.T) {
+
+	s := NewLRUSessionStore(2)
+
+	now := time.Now()
+
+	s.Set("a", now)
+	s.Set("b", now)
+
+	if _, ok := s.Get("a"); !ok {
+		t.Fatal("expected a")
+	}
+
+	// evicts b, the least recently used
+	s.Set("c", now)
+
+	if _, ok := s.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+
+	if last, ok := s.Get("a"); !ok || !last.Equal(now) {
+		t.Fatal(last, ok)
+	}
+
+	s.Delete("a")
+
+	if _, ok := s.Get("a"); ok {
+		t.Fatal("expected a to be deleted")
+	}
+
+}
@@ -0,0 +1,257 @@
+//go:build go1.21
+// +build go1.21
+
+package ga
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+)
+
+// SlogRule selects log records to report as GA events and maps their attributes to event fields.
+// Attribute keys within groups are qualified with the group names, as in "request.method".
+type SlogRule struct {
+	// Level is the minimum level of matching records. A nil Level matches all levels.
+	Level slog.Leveler
+	// Message, if set, must equal the message of matching records.
+	Message string
+	// Attr, if set, is the key of an attribute matching records must carry.
+	Attr string
+
+	// Category is the event category ("ec"). The default is the level of the record, such as "INFO".
+	Category string
+	// ActionKey is the key of the attribute reported as event action ("ea").
+	// The default action is the message of the record.
+	ActionKey string
+	// LabelKey is the key of the attribute reported as event label ("el").
+	LabelKey string
+	// ValueKey is the key of the attribute reported as event value ("ev").
+	// The value must be an integer, floats are rounded, other values are dropped.
+	ValueKey string
+	// Dimensions maps custom dimension indexes to attribute keys.
+	Dimensions map[int]string
+	// Metrics maps custom metric indexes to attribute keys.
+	Metrics map[int]string
+}
+
+// SlogOptions configures the handler returned by NewSlogHandler.
+type SlogOptions struct {
+	// Rules select the records to report. A record is reported once, by the first matching rule.
+	Rules []SlogRule
+}
+
+// slogHandler is a slog.Handler that reports matching records with a Client
+// and passes all records to an inner handler.
+type slogHandler struct {
+	client *Client
+	inner  slog.Handler
+	rules  []SlogRule
+	attrs  []slog.Attr // qualified attributes added with WithAttrs
+	group  string      // qualifier of attributes added after WithGroup
+}
+
+// NewSlogHandler returns a slog.Handler that reports log records matching opts as event hits with c
+// and passes all records to inner, so normal logging continues.
+// When the context of a record carries a Tracker, the event is reported for its visitor.
+// Errors reporting events are passed to the ErrHandler of c, they never fail logging.
+// It requires Go 1.21 or later, which added log/slog.
+func NewSlogHandler(c *Client, inner slog.Handler, opts *SlogOptions) slog.Handler {
+	h := &slogHandler{
+		client: c,
+		inner:  inner,
+	}
+	if opts != nil {
+		h.rules = opts.Rules
+	}
+	return h
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.inner.Enabled(ctx, level) {
+		return true
+	}
+
+	for _, rule := range h.rules {
+		if rule.Level == nil || level >= rule.Level.Level() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.report(ctx, r)
+
+	if !h.inner.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.inner = h.inner.WithAttrs(attrs)
+	h2.attrs = make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(h2.attrs, h.attrs)
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.group, a)
+	}
+	return &h2
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.inner = h.inner.WithGroup(name)
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// report reports r with the first matching rule.
+func (h *slogHandler) report(ctx context.Context, r slog.Record) {
+	if len(h.rules) == 0 {
+		return
+	}
+
+	var attrs map[string]slog.Value
+
+	for _, rule := range h.rules {
+		if rule.Level != nil && r.Level < rule.Level.Level() {
+			continue
+		}
+		if rule.Message != "" && r.Message != rule.Message {
+			continue
+		}
+
+		if attrs == nil {
+			attrs = h.collect(r)
+		}
+
+		if rule.Attr != "" {
+			if _, ok := attrs[rule.Attr]; !ok {
+				continue
+			}
+		}
+
+		e := rule.event(r, attrs)
+
+		var err error
+		if t := FromContext(ctx); t != nil {
+			err = t.Report(e)
+		} else {
+			base := h.client.base()
+			for k, v := range e {
+				base.Set(k, v)
+			}
+			e = base
+			err = h.client.Report(e)
+		}
+		if err != nil {
+			h.client.handleErr([]Event{e}, err)
+		}
+
+		return
+	}
+}
+
+// collect returns the attributes of r and h by qualified key.
+func (h *slogHandler) collect(r slog.Record) map[string]slog.Value {
+	l := make([]slog.Attr, len(h.attrs), len(h.attrs)+r.NumAttrs())
+	copy(l, h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		l = appendAttr(l, h.group, a)
+		return true
+	})
+
+	attrs := make(map[string]slog.Value, len(l))
+	for _, a := range l {
+		attrs[a.Key] = a.Value
+	}
+	return attrs
+}
+
+// appendAttr appends a to l with its key qualified by group, flattening groups.
+func appendAttr(l []slog.Attr, group string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			l = appendAttr(l, prefix, ga)
+		}
+		return l
+	}
+
+	if a.Key == "" {
+		return l
+	}
+
+	a.Key = group + a.Key
+	return append(l, a)
+}
+
+func (rule SlogRule) event(r slog.Record, attrs map[string]slog.Value) Event {
+	e := Event{
+		"t":  "event",
+		"ec": rule.Category,
+		"ea": r.Message,
+	}
+	if e.Get("ec") == "" {
+		e.Set("ec", r.Level.String())
+	}
+
+	if v, ok := attrs[rule.ActionKey]; ok && rule.ActionKey != "" {
+		e.Set("ea", v.String())
+	}
+	if v, ok := attrs[rule.LabelKey]; ok && rule.LabelKey != "" {
+		e.Set("el", v.String())
+	}
+	if v, ok := attrs[rule.ValueKey]; ok && rule.ValueKey != "" {
+		if ev, ok := slogInt(v); ok {
+			e.Set("ev", ev)
+		}
+	}
+
+	for i, key := range rule.Dimensions {
+		if v, ok := attrs[key]; ok {
+			e.Set("cd"+strconv.Itoa(i), v.String())
+		}
+	}
+	for i, key := range rule.Metrics {
+		if v, ok := attrs[key]; ok {
+			e.Set("cm"+strconv.Itoa(i), v.String())
+		}
+	}
+
+	return e
+}
+
+// slogInt formats v as an integer, as required for the event value.
+func slogInt(v slog.Value) (string, bool) {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return strconv.FormatInt(v.Int64(), 10), true
+	case slog.KindUint64:
+		return strconv.FormatUint(v.Uint64(), 10), true
+	case slog.KindFloat64:
+		return strconv.FormatInt(int64(math.Round(v.Float64())), 10), true
+	case slog.KindString:
+		if _, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return v.String(), true
+		}
+	}
+	return "", false
+}
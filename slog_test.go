@@ -0,0 +1,83 @@
+//go:build go1.21
+// +build go1.21
+
+package ga
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func Test_SlogHandler(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+
+	var buf bytes.Buffer
+	inner := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+
+	logger := slog.New(NewSlogHandler(c, inner, &SlogOptions{
+		Rules: []SlogRule{
+			{
+				Message:    "signup",
+				Category:   "account",
+				LabelKey:   "plan",
+				ValueKey:   "order.total",
+				Dimensions: map[int]string{3: "order.country"},
+			},
+			{
+				Level: slog.LevelDebug,
+				Attr:  "ga",
+			},
+		},
+	}))
+
+	logger.Info("signup", "plan", "pro", slog.Group("order", "total", 9.6, "country", "BE"))
+	logger.With("ga", true).WithGroup("job").Debug("cleanup", "removed", 3)
+	logger.Info("unrelated")
+
+	if !strings.Contains(buf.String(), "msg=signup") || !strings.Contains(buf.String(), "msg=unrelated") || strings.Contains(buf.String(), "cleanup") {
+		t.Fatal(buf.String())
+	}
+
+	if len(c.pending) != 2 {
+		t.Fatal(len(c.pending))
+	}
+
+	e := c.pending[0].e
+	if e.Get("t") != "event" || e.Get("cid") == "" || e.Get("ec") != "account" || e.Get("ea") != "signup" || e.Get("el") != "pro" || e.Get("ev") != "10" || e.Get("cd3") != "BE" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[1].e
+	if e.Get("ec") != "DEBUG" || e.Get("ea") != "cleanup" || e.Get("el") != "" {
+		t.Fatal(e)
+	}
+
+}
+
+func Test_SlogHandler_Tracker(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+	tracker := NewTracker(c, Event{"v": "1", "tid": c.TID, "cid": "35009a79-1a05-49d7-b876-2b884d0f825b"})
+	ctx := NewContext(context.Background(), tracker)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(c, slog.NewTextHandler(&buf, nil), &SlogOptions{
+		Rules: []SlogRule{{Attr: "request.id"}},
+	}))
+
+	logger.WithGroup("request").InfoContext(ctx, "checkout", "id", "abc")
+
+	if len(c.pending) != 1 {
+		t.Fatal(len(c.pending))
+	}
+
+	e := c.pending[0].e
+	if e.Get("cid") != "35009a79-1a05-49d7-b876-2b884d0f825b" || e.Get("ea") != "checkout" {
+		t.Fatal(e)
+	}
+
+}
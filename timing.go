@@ -0,0 +1,55 @@
+package ga
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pborman/uuid"
+)
+
+// base returns the fields of Events the Client builds itself.
+func (c *Client) base() Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.CID == "" {
+		c.CID = uuid.New()
+	}
+
+	return Event{
+		"v":   "1",
+		"tid": c.TID,
+		"cid": c.CID,
+	}
+}
+
+// ReportTiming is used to submit a user timing hit to GA.
+// The duration is reported in milliseconds, label is optional.
+func (c *Client) ReportTiming(category, variable, label string, d time.Duration) error {
+	e := c.base()
+	e.Set("t", "timing")
+	e.Set("utc", category)
+	e.Set("utv", variable)
+	e.Set("utt", strconv.FormatInt(int64(d/time.Millisecond), 10))
+	e.Set("utl", label)
+
+	return c.Report(e)
+}
+
+// Time starts measuring a duration and returns a function that reports it as a user timing hit.
+//
+//	defer c.Time("jobs", "cleanup")()
+func (c *Client) Time(category, variable string) func() error {
+	start := time.Now()
+
+	return func() error {
+		return c.ReportTiming(category, variable, "", time.Since(start))
+	}
+}
+
+// TimeFunc calls f and reports how long it took as a user timing hit.
+func (c *Client) TimeFunc(category, variable string, f func()) error {
+	stop := c.Time(category, variable)
+	f()
+	return stop()
+}
@@ -0,0 +1,50 @@
+package ga
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_Client_Timing(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+
+	err := c.ReportTiming("db", "query", "users", time.Millisecond*1500)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.TimeFunc("jobs", "sleep", func() {
+		time.Sleep(time.Millisecond * 20)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := c.Time("jobs", "noop")
+	err = stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.pending) != 3 {
+		t.Fatal(len(c.pending))
+	}
+
+	e := c.pending[0].e
+	if e.Get("t") != "timing" || e.Get("tid") != "UA-12345-1" || e.Get("cid") == "" || e.Get("utc") != "db" || e.Get("utv") != "query" || e.Get("utl") != "users" || e.Get("utt") != "1500" {
+		t.Fatal(e)
+	}
+
+	e = c.pending[1].e
+	if utt, _ := strconv.Atoi(e.Get("utt")); utt < 20 || e.Get("utv") != "sleep" || e.Get("cid") != c.pending[0].e.Get("cid") {
+		t.Fatal(e)
+	}
+
+	e = c.pending[2].e
+	if e.Get("utt") != "0" || e.Get("utv") != "noop" {
+		t.Fatal(e)
+	}
+
+}
@@ -0,0 +1,65 @@
+package ga
+
+import (
+	"context"
+)
+
+// Tracker reports Events on behalf of a single visitor.
+// It fills in the GA ID ("tid"), client id ("cid"), user id ("uid"), IP address ("uip")
+// and user agent ("ua") of the visitor when they are not set on the reported Event.
+//
+// Middleware stores a Tracker in the context of every request it handles.
+type Tracker struct {
+	client   *Client
+	base     Event
+	sessions *SessionTracker
+}
+
+// NewTracker returns a Tracker that reports to c and fills in the keys of base.
+func NewTracker(c *Client, base Event) *Tracker {
+	return &Tracker{client: c, base: base}
+}
+
+// ClientID returns the client id of the visitor.
+func (t *Tracker) ClientID() string {
+	if t == nil {
+		return ""
+	}
+	return t.base.Get("cid")
+}
+
+// Report is used to submit an Event to GA for the visitor.
+// It returns ErrNoTracker on a nil Tracker.
+func (t *Tracker) Report(e Event) error {
+	if t == nil {
+		return ErrNoTracker
+	}
+
+	for k, v := range t.base {
+		if v != "" && e.Get(k) == "" {
+			e.Set(k, v)
+		}
+	}
+
+	if t.sessions != nil {
+		e = t.sessions.Process(e)
+	}
+
+	return t.client.Report(e)
+}
+
+type trackerKey struct{}
+
+// NewContext returns a copy of ctx that carries t.
+func NewContext(ctx context.Context, t *Tracker) context.Context {
+	return context.WithValue(ctx, trackerKey{}, t)
+}
+
+// FromContext returns the Tracker carried by ctx, or nil.
+// Reporting to a nil Tracker returns ErrNoTracker, so the result can be used directly:
+//
+//	ga.FromContext(r.Context()).Report(ga.Event{"t": "event", "ec": "account", "ea": "signup"})
+func FromContext(ctx context.Context) *Tracker {
+	t, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return t
+}
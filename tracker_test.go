@@ -0,0 +1,79 @@
+package ga
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Tracker_FromContext(t *testing.T) {
+
+	c := &Client{TID: "UA-12345-1"}
+
+	m := &Middleware{
+		Client:         c,
+		TrustForwarded: true,
+		UserID: func(r *http.Request) (string, bool) {
+			return "alice", true
+		},
+	}
+
+	h := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := FromContext(r.Context()).Report(Event{
+			"t":  "event",
+			"ec": "account",
+			"ea": "signup",
+			"ua": "override",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}))
+
+	r := httptest.NewRequest("GET", "/signup", nil)
+	r.Header.Set("User-Agent", "test-agent")
+	r.Header.Set("X-Forwarded-For", "10.0.0.1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(c.pending) != 2 {
+		t.Fatal(len(c.pending))
+	}
+
+	pageview, e := c.pending[0].e, c.pending[1].e
+
+	for _, k := range []string{"v", "tid", "cid", "uid", "uip"} {
+		if e.Get(k) == "" || e.Get(k) != pageview.Get(k) {
+			t.Fatal(k, e, pageview)
+		}
+	}
+
+	if e.Get("uip") != "10.0.0.1" || e.Get("uid") != "alice" || e.Get("t") != "event" || e.Get("ea") != "signup" {
+		t.Fatal(e)
+	}
+
+	if pageview.Get("ua") != "test-agent" || e.Get("ua") != "override" {
+		t.Fatal(pageview, e)
+	}
+
+}
+
+func Test_Tracker_Nil(t *testing.T) {
+
+	tr := FromContext(context.Background())
+
+	if tr.ClientID() != "" {
+		t.Fatal(tr.ClientID())
+	}
+
+	err := tr.Report(Event{"t": "event"})
+	if err != ErrNoTracker {
+		t.Fatal(err)
+	}
+
+	tr = NewTracker(&Client{}, Event{"cid": "abc"})
+	if FromContext(NewContext(context.Background(), tr)).ClientID() != "abc" {
+		t.Fatal("expected tracker")
+	}
+
+}
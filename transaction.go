@@ -0,0 +1,147 @@
+package ga
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Transaction is a classic ecommerce transaction with its items.
+type Transaction struct {
+	// ID is the transaction id ("ti"), it is required.
+	ID          string
+	Affiliation string
+	Revenue     float64
+	Shipping    float64
+	Tax         float64
+	// Currency is the ISO 4217 currency code of the amounts.
+	Currency string
+	Items    []Item
+}
+
+// Item is a single item of a Transaction.
+type Item struct {
+	// Name is the item name ("in"), it is required.
+	Name     string
+	Code     string
+	Category string
+	Price    float64
+	Quantity int
+}
+
+// maxTransactionItems keeps a transaction and its items within a single batch.
+const maxTransactionItems = 19
+
+// Validate reports whether tx can be reported.
+func (tx Transaction) Validate() error {
+	if tx.ID == "" {
+		return errors.Wrap(ErrInvalidTransaction, "missing id")
+	}
+
+	if len(tx.Items) > maxTransactionItems {
+		return errors.Wrapf(ErrInvalidTransaction, "%d items, at most %d fit in a batch", len(tx.Items), maxTransactionItems)
+	}
+
+	for i, item := range tx.Items {
+		if item.Name == "" {
+			return errors.Wrapf(ErrInvalidTransaction, "item %d: missing name", i)
+		}
+	}
+
+	return nil
+}
+
+// Events returns copies of base for the transaction hit and an item hit per Item.
+func (tx Transaction) Events(base Event) []Event {
+	l := make([]Event, 0, len(tx.Items)+1)
+
+	t := copyEvent(base)
+	t.Set("t", "transaction")
+	t.Set("ti", tx.ID)
+	t.Set("ta", tx.Affiliation)
+	t.Set("tr", formatFloat(tx.Revenue))
+	t.Set("ts", formatFloat(tx.Shipping))
+	t.Set("tt", formatFloat(tx.Tax))
+	t.Set("cu", tx.Currency)
+	l = append(l, t)
+
+	for _, item := range tx.Items {
+		e := copyEvent(base)
+		e.Set("t", "item")
+		e.Set("ti", tx.ID)
+		e.Set("in", item.Name)
+		e.Set("ic", item.Code)
+		e.Set("iv", item.Category)
+		e.Set("ip", formatFloat(item.Price))
+		if item.Quantity != 0 {
+			e.Set("iq", strconv.Itoa(item.Quantity))
+		}
+		e.Set("cu", tx.Currency)
+		l = append(l, e)
+	}
+
+	return l
+}
+
+// ReportTransaction is used to submit a Transaction to GA as a transaction hit and an item hit per Item,
+// each a copy of base.
+// The hits are always submitted in the same batch, so they succeed or fail together,
+// and a failure passes all of them to the ErrHandler.
+// This can be safely called by multiple go routines.
+func (c *Client) ReportTransaction(base Event, tx Transaction) error {
+	err := tx.Validate()
+	if err != nil {
+		return err
+	}
+
+	if !c.accepting() {
+		return ErrClientClosed
+	}
+
+	now := time.Now()
+
+	var l []event
+	for _, e := range tx.Events(base) {
+		e = c.process(e)
+		if e == nil {
+			continue
+		}
+
+		l = append(l, event{
+			reportedAt: now,
+			e:          e,
+		})
+	}
+
+	if len(l) == 0 {
+		return nil
+	}
+
+	return c.enqueue(l...)
+}
+
+// ReportTransaction is used to submit a Transaction to GA for the visitor.
+// See Client.ReportTransaction.
+func (t *Tracker) ReportTransaction(tx Transaction) error {
+	if t == nil {
+		return ErrNoTracker
+	}
+
+	base := Event{}
+	for k, v := range t.base {
+		if v != "" {
+			base.Set(k, v)
+		}
+	}
+
+	return t.client.ReportTransaction(base, tx)
+}
+
+func copyEvent(e Event) Event {
+	x := make(Event, len(e))
+	for k, v := range e {
+		x[k] = v
+	}
+	return x
+}
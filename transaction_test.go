@@ -0,0 +1,174 @@
+package ga
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func Test_Transaction_Validate(t *testing.T) {
+
+	tests := []Transaction{
+		{},
+		{ID: "T1", Items: []Item{{Code: "a"}}},
+		{ID: "T1", Items: make([]Item, 20)},
+	}
+
+	for i, tx := range tests {
+		if errors.Cause(tx.Validate()) != ErrInvalidTransaction {
+			t.Fatal(i, tx.Validate())
+		}
+	}
+
+	tx := Transaction{ID: "T1", Items: []Item{{Name: "a"}}}
+	if tx.Validate() != nil {
+		t.Fatal(tx.Validate())
+	}
+
+}
+
+func Test_Transaction_Events(t *testing.T) {
+
+	tx := Transaction{
+		ID:       "T1",
+		Revenue:  11.99,
+		Currency: "EUR",
+		Items: []Item{
+			{Name: "Shirt", Code: "SKU1", Price: 11.99, Quantity: 1},
+		},
+	}
+
+	l := tx.Events(Event{"tid": "UA-12345-1", "cid": "abc"})
+	if len(l) != 2 {
+		t.Fatal(l)
+	}
+
+	if l[0].Get("t") != "transaction" || l[0].Get("ti") != "T1" || l[0].Get("tr") != "11.99" || l[0].Get("cu") != "EUR" || l[0].Get("cid") != "abc" {
+		t.Fatal(l[0])
+	}
+
+	if l[1].Get("t") != "item" || l[1].Get("ti") != "T1" || l[1].Get("in") != "Shirt" || l[1].Get("ic") != "SKU1" || l[1].Get("ip") != "11.99" || l[1].Get("iq") != "1" || l[1].Get("tid") != "UA-12345-1" {
+		t.Fatal(l[1])
+	}
+
+}
+
+func Test_BatchEnd(t *testing.T) {
+
+	l := make(events, 30)
+	if batchEnd(l) != 20 || batchEnd(l[:7]) != 7 {
+		t.Fatal(batchEnd(l))
+	}
+
+	for i := 15; i < 25; i++ {
+		l[i].group = 1
+	}
+	if batchEnd(l) != 15 {
+		t.Fatal(batchEnd(l))
+	}
+
+	for i := 0; i < 25; i++ {
+		l[i].group = 2
+	}
+	if batchEnd(l) != 20 {
+		t.Fatal(batchEnd(l))
+	}
+
+}
+
+func Test_Client_ReportTransaction(t *testing.T) {
+
+	reqChan := make(chan string, 10)
+	errChan := make(chan []Event, 10)
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer r.Body.Close()
+			b, _ := ioutil.ReadAll(r.Body)
+			reqChan <- string(b)
+
+			if strings.Contains(string(b), "ti=FAIL") {
+				http.Error(w, "bad", 400)
+			}
+		}),
+	)
+	defer ts.Close()
+
+	c := &Client{
+		BatchWait: time.Hour,
+	}
+
+	c.urlStr = ts.URL
+
+	c.HandleErr(ErrHandlerFunc(func(e []Event, err error) {
+		errChan <- e
+	}))
+
+	go c.Start()
+
+	time.Sleep(time.Millisecond * 10)
+
+	for i := 0; i < 15; i++ {
+		c.Report(Event{"foo": fmt.Sprint(i)})
+	}
+
+	items := make([]Item, 10)
+	for i := range items {
+		items[i] = Item{Name: fmt.Sprint("item", i)}
+	}
+
+	err := c.ReportTransaction(Event{"cid": "abc"}, Transaction{ID: "T1", Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, second := <-reqChan, <-reqChan
+
+	if n := len(strings.Split(first, "\n")); n != 15 {
+		t.Fatal(n, first)
+	}
+	if n := len(strings.Split(second, "\n")); n != 11 || !regexp.MustCompile(`^cid=abc&(qt=\d+&)?t=transaction&ti=T1\n`).MatchString(second) {
+		t.Fatal(n, second)
+	}
+
+	// the whole group fails together
+	err = c.ReportTransaction(Event{}, Transaction{ID: "FAIL", Items: items[:3]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Flush(context.Background())
+
+	select {
+	case e := <-errChan:
+		if len(e) != 4 {
+			t.Fatal(e)
+		}
+	default:
+		t.Fatal("expected err")
+	}
+
+	err = c.ReportTransaction(Event{}, Transaction{})
+	if errors.Cause(err) != ErrInvalidTransaction {
+		t.Fatal(err)
+	}
+
+	err = c.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}